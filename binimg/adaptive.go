@@ -0,0 +1,141 @@
+package binimg
+
+import (
+	"image"
+	"math"
+)
+
+// NewMeanC returns a new binary image that is the conversion of src image
+// using a local threshold. A pixel is On if its Rec.601 luminance is greater
+// than the mean luminance of the window centered on it, minus c.
+//
+// window is the side, in pixels, of the square window, even values being
+// rounded up to the next odd value. Windows are clipped to the image bounds.
+func NewMeanC(src image.Image, window int, c float64) *Image {
+	return adaptive(src, window, func(mean, stddev float64) float64 {
+		return mean - c
+	})
+}
+
+// NewNiblack returns a new binary image that is the conversion of src image
+// using Niblack's local threshold. A pixel is On if its Rec.601 luminance is
+// greater than m + k*s, m and s being the mean and standard deviation of the
+// luminance in the window centered on it. k is generally negative, typically
+// -0.2.
+//
+// window is the side, in pixels, of the square window, even values being
+// rounded up to the next odd value. Windows are clipped to the image bounds.
+func NewNiblack(src image.Image, window int, k float64) *Image {
+	return adaptive(src, window, func(mean, stddev float64) float64 {
+		return mean + k*stddev
+	})
+}
+
+// NewSauvola returns a new binary image that is the conversion of src image
+// using Sauvola's local threshold. A pixel is On if its Rec.601 luminance is
+// greater than m * (1 + k*(s/r - 1)), m and s being the mean and standard
+// deviation of the luminance in the window centered on it. r is the dynamic
+// range of the standard deviation, typically 128, and k is typically 0.5.
+//
+// window is the side, in pixels, of the square window, even values being
+// rounded up to the next odd value. Windows are clipped to the image bounds.
+func NewSauvola(src image.Image, window int, k, r float64) *Image {
+	return adaptive(src, window, func(mean, stddev float64) float64 {
+		return mean * (1 + k*(stddev/r-1))
+	})
+}
+
+// adaptive converts src to a binary image, the threshold of each pixel being
+// computed by threshold from the mean and standard deviation of the luminance
+// of the window centered on it.
+//
+// The sums of the luminances and of their squares are computed with integral
+// images, so that the cost of the conversion doesn't depend on the window
+// size.
+func adaptive(src image.Image, window int, threshold func(mean, stddev float64) float64) *Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dst := New(b)
+	if w <= 0 || h <= 0 {
+		return dst
+	}
+	luma := lumaPlane(src)
+
+	// integral images of the luminance and its square, with an extra row
+	// and column of zeroes so that sum and sq at (x+1, y+1) hold the sums
+	// of the region (0, 0)-(x, y).
+	stride := w + 1
+	sum := make([]uint64, stride*(h+1))
+	sq := make([]uint64, stride*(h+1))
+	for y := 0; y < h; y++ {
+		var rowsum, rowsq uint64
+		for x := 0; x < w; x++ {
+			v := uint64(luma[y*w+x])
+			rowsum += v
+			rowsq += v * v
+			i := (y+1)*stride + x + 1
+			sum[i] = sum[i-stride] + rowsum
+			sq[i] = sq[i-stride] + rowsq
+		}
+	}
+
+	half := window / 2
+	if half < 0 {
+		half = 0
+	}
+	for y := 0; y < h; y++ {
+		y0, y1 := clamp(y-half, 0, h), clamp(y+half+1, 0, h)
+		i := dst.PixOffset(b.Min.X, b.Min.Y+y)
+		for x := 0; x < w; x++ {
+			x0, x1 := clamp(x-half, 0, w), clamp(x+half+1, 0, w)
+			n := float64((x1 - x0) * (y1 - y0))
+			tl, tr, bl, br := y0*stride+x0, y0*stride+x1, y1*stride+x0, y1*stride+x1
+			s := float64(sum[br] + sum[tl] - sum[tr] - sum[bl])
+			s2 := float64(sq[br] + sq[tl] - sq[tr] - sq[bl])
+			mean := s / n
+			variance := s2/n - mean*mean
+			if variance < 0 {
+				variance = 0
+			}
+			if float64(luma[y*w+x]) > threshold(mean, math.Sqrt(variance)) {
+				dst.Pix[i] = On.V
+			}
+			i++
+		}
+	}
+	return dst
+}
+
+// lumaPlane returns the 8-bit Rec.601 luminance of the pixels of src, line
+// after line.
+func lumaPlane(src image.Image) []uint8 {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	plane := make([]uint8, w*h)
+	if gray, ok := src.(*image.Gray); ok {
+		for y := 0; y < h; y++ {
+			i := gray.PixOffset(b.Min.X, b.Min.Y+y)
+			copy(plane[y*w:], gray.Pix[i:i+w])
+		}
+		return plane
+	}
+	i := 0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			plane[i] = uint8(Rec601.luma(src.At(x, y)) >> 8)
+			i++
+		}
+	}
+	return plane
+}
+
+// clamp returns v clamped to [min, max].
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,138 @@
+package binimg
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// naiveAdaptive is the straightforward implementation of adaptive, computing
+// the mean and standard deviation of each window pixel per pixel.
+func naiveAdaptive(src *image.Gray, window int, threshold func(mean, stddev float64) float64) *Image {
+	b := src.Bounds()
+	dst := New(b)
+	half := window / 2
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r := image.Rect(x-half, y-half, x+half+1, y+half+1).Intersect(b)
+			var sum, sq, n float64
+			for wy := r.Min.Y; wy < r.Max.Y; wy++ {
+				for wx := r.Min.X; wx < r.Max.X; wx++ {
+					v := float64(src.GrayAt(wx, wy).Y)
+					sum += v
+					sq += v * v
+					n++
+				}
+			}
+			mean := sum / n
+			stddev := math.Sqrt(math.Max(0, sq/n-mean*mean))
+			if float64(src.GrayAt(x, y).Y) > threshold(mean, stddev) {
+				dst.SetBit(x, y, On)
+			}
+		}
+	}
+	return dst
+}
+
+func TestAdaptiveThresholds(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	src := image.NewGray(image.Rect(-3, 2, 37, 31))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(rnd.Intn(256))
+	}
+
+	var tests = []struct {
+		name      string
+		threshold func(mean, stddev float64) float64
+		convert   func(src image.Image, window int) *Image
+	}{
+		{
+			"mean-c",
+			func(mean, stddev float64) float64 { return mean - 7 },
+			func(src image.Image, window int) *Image { return NewMeanC(src, window, 7) },
+		},
+		{
+			"niblack",
+			func(mean, stddev float64) float64 { return mean - 0.2*stddev },
+			func(src image.Image, window int) *Image { return NewNiblack(src, window, -0.2) },
+		},
+		{
+			"sauvola",
+			func(mean, stddev float64) float64 { return mean * (1 + 0.5*(stddev/128-1)) },
+			func(src image.Image, window int) *Image { return NewSauvola(src, window, 0.5, 128) },
+		},
+	}
+
+	for _, tt := range tests {
+		for _, window := range []int{1, 3, 8, 15, 101} {
+			want := naiveAdaptive(src, window, tt.threshold)
+			got := tt.convert(src, window)
+			if got.Rect != src.Rect {
+				t.Errorf("%s, window %d: want bounds %v, got %v", tt.name, window, src.Rect, got.Rect)
+			}
+			for y := src.Rect.Min.Y; y < src.Rect.Max.Y; y++ {
+				for x := src.Rect.Min.X; x < src.Rect.Max.X; x++ {
+					if want.BitAt(x, y) != got.BitAt(x, y) {
+						t.Fatalf("%s, window %d: want %v at (%d,%d), got %v", tt.name, window, want.BitAt(x, y), x, y, got.BitAt(x, y))
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAdaptiveUnevenLighting(t *testing.T) {
+	// dark text strokes on a background whose brightness goes from 40 to 240
+	// from left to right, the text being 30 levels darker than the background.
+	src := image.NewRGBA(image.Rect(0, 0, 200, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 200; x++ {
+			v := uint8(40 + x)
+			if x%10 < 2 && y > 10 && y < 30 {
+				v -= 30
+			}
+			src.Set(x, y, color.RGBA{v, v, v, 255})
+		}
+	}
+
+	var tests = []struct {
+		name string
+		bin  *Image
+	}{
+		{"mean-c", NewMeanC(src, 15, 5)},
+		{"niblack", NewNiblack(src, 15, -0.2)},
+		{"sauvola", NewSauvola(src, 15, 0.1, 128)},
+	}
+
+	for _, tt := range tests {
+		for x := 0; x < 200; x++ {
+			want := On
+			if x%10 < 2 {
+				want = Off
+			}
+			if got := tt.bin.BitAt(x, 20); got != want {
+				t.Errorf("%s: want %v at (%d,20), got %v", tt.name, want, x, got)
+			}
+		}
+	}
+
+	// a global threshold loses the text in the dark and bright areas: the
+	// dark background is Off like the strokes, and the bright strokes are On
+	// like the background.
+	global := NewFromImage(src)
+	if global.BitAt(3, 20) != Off || global.BitAt(4, 20) != Off {
+		t.Errorf("want global threshold to lose dark background")
+	}
+	if global.BitAt(190, 20) != On || global.BitAt(191, 20) != On {
+		t.Errorf("want global threshold to lose bright text strokes")
+	}
+}
+
+func TestAdaptiveEmpty(t *testing.T) {
+	bin := NewSauvola(image.NewGray(image.Rect(3, 3, 3, 10)), 15, 0.5, 128)
+	if !bin.Bounds().Empty() {
+		t.Errorf("want empty image, got bounds %v", bin.Bounds())
+	}
+}
@@ -0,0 +1,122 @@
+package binimg
+
+import "image"
+
+// A BoolOp is a boolean operation between the pixels of 2 binary images, On
+// pixels being true.
+type BoolOp int
+
+// Boolean operations.
+const (
+	// And is On if both pixels are On.
+	And BoolOp = iota
+	// Or is On if any of the pixels is On.
+	Or
+	// Xor is On if exactly one of the pixels is On.
+	Xor
+	// AndNot is On if the first pixel is On and the second is Off.
+	AndNot
+)
+
+// Combine returns a new image whose pixels are the combination with op of
+// the pixels of a and b. Its bounds are the intersection of the bounds of a
+// and b, and its palette is the palette of a.
+func Combine(a, b *Image, op BoolOp) *Image {
+	r := a.Rect.Intersect(b.Rect)
+	dst := NewWithPalette(r, a.Palette)
+	CombineTo(dst, r, a, r.Min, b, r.Min, op)
+	return dst
+}
+
+// CombineTo sets the pixels of dst in r to the combination with op of the
+// pixels of a and b, the pixel of dst at r.Min corresponding to the pixels
+// of a at ap and of b at bp. r is clipped so that the pixels of dst, a and b
+// are all in their respective bounds, pixels out of r being left unchanged.
+func CombineTo(dst *Image, r image.Rectangle, a *Image, ap image.Point, b *Image, bp image.Point, op BoolOp) {
+	r = clip(r, dst.Rect, a.Rect, &ap)
+	r = clip(r, dst.Rect, b.Rect, &bp)
+	// clipping to b may have shrunk r further, ap has to follow
+	r = clip(r, dst.Rect, a.Rect, &ap)
+	for y := 0; y < r.Dy(); y++ {
+		di := dst.PixOffset(r.Min.X, r.Min.Y+y)
+		ai := a.PixOffset(ap.X, ap.Y+y)
+		bi := b.PixOffset(bp.X, bp.Y+y)
+		combineRow(dst.Pix[di:di+r.Dx()], a.Pix[ai:ai+r.Dx()], b.Pix[bi:bi+r.Dx()], op)
+	}
+}
+
+// Apply sets the pixels of dst in r to their combination with op with the
+// pixels of src, the pixel of dst at r.Min corresponding to the pixel of src
+// at sp. r is clipped like in CombineTo. Apply doesn't allocate.
+func Apply(dst *Image, r image.Rectangle, src *Image, sp image.Point, op BoolOp) {
+	r = clip(r, dst.Rect, src.Rect, &sp)
+	for y := 0; y < r.Dy(); y++ {
+		di := dst.PixOffset(r.Min.X, r.Min.Y+y)
+		si := src.PixOffset(sp.X, sp.Y+y)
+		row := dst.Pix[di : di+r.Dx()]
+		combineRow(row, row, src.Pix[si:si+r.Dx()], op)
+	}
+}
+
+// Not returns a new image, with the same bounds and palette as a, whose
+// pixels are the inverse of the pixels of a.
+func Not(a *Image) *Image {
+	dst := NewWithPalette(a.Rect, a.Palette)
+	for y := a.Rect.Min.Y; y < a.Rect.Max.Y; y++ {
+		si := a.PixOffset(a.Rect.Min.X, y)
+		di := dst.PixOffset(a.Rect.Min.X, y)
+		invertRow(dst.Pix[di:di+a.Rect.Dx()], a.Pix[si:si+a.Rect.Dx()])
+	}
+	return dst
+}
+
+// Invert inverts the pixels of dst in r. Invert doesn't allocate.
+func Invert(dst *Image, r image.Rectangle) {
+	r = r.Intersect(dst.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := dst.PixOffset(r.Min.X, y)
+		row := dst.Pix[i : i+r.Dx()]
+		invertRow(row, row)
+	}
+}
+
+// clip clips r to dst and to src, sp being the point of src corresponding to
+// r.Min, and updated accordingly.
+func clip(r, dst, src image.Rectangle, sp *image.Point) image.Rectangle {
+	orig := r.Min
+	r = r.Intersect(dst)
+	r = r.Intersect(src.Add(orig.Sub(*sp)))
+	*sp = sp.Add(r.Min.Sub(orig))
+	return r
+}
+
+// combineRow sets dst to the combination with op of a and b, 3 rows of the
+// same length. As pixels are either 0x00 or 0xff, boolean operations are
+// performed bitwise.
+func combineRow(dst, a, b []uint8, op BoolOp) {
+	switch op {
+	case And:
+		for i := range dst {
+			dst[i] = a[i] & b[i]
+		}
+	case Or:
+		for i := range dst {
+			dst[i] = a[i] | b[i]
+		}
+	case Xor:
+		for i := range dst {
+			dst[i] = a[i] ^ b[i]
+		}
+	case AndNot:
+		for i := range dst {
+			dst[i] = a[i] &^ b[i]
+		}
+	}
+}
+
+// invertRow sets dst to the inverse of src, 2 rows of the same length.
+func invertRow(dst, src []uint8) {
+	for i := range dst {
+		dst[i] = ^src[i]
+	}
+}
@@ -0,0 +1,135 @@
+package binimg
+
+import (
+	"image"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestCombine(t *testing.T) {
+	a := newFromString(image.Pt(0, 0), []string{
+		"1100",
+		"1100",
+		"0000",
+	})
+	b := newFromString(image.Pt(1, 1), []string{
+		"111",
+		"001",
+		"111",
+	})
+
+	var tests = []struct {
+		op   BoolOp
+		want []string
+	}{
+		{And, []string{"100", "000"}},
+		{Or, []string{"111", "001"}},
+		{Xor, []string{"011", "001"}},
+		{AndNot, []string{"000", "000"}},
+	}
+
+	for _, tt := range tests {
+		got := Combine(a, b, tt.op)
+		want := newFromString(image.Pt(1, 1), tt.want)
+		if got.Rect != want.Rect {
+			t.Errorf("op %d: want bounds %v, got %v", tt.op, want.Rect, got.Rect)
+		}
+		if err := test.Diff(want, got); err != nil {
+			t.Errorf("op %d: %v", tt.op, err)
+		}
+	}
+
+	// AndNot is not commutative
+	want := newFromString(image.Pt(1, 1), []string{"011", "001"})
+	if err := test.Diff(want, Combine(b, a, AndNot)); err != nil {
+		t.Errorf("b AndNot a: %v", err)
+	}
+}
+
+func TestCombineTo(t *testing.T) {
+	a := newFromString(image.Pt(-2, -2), []string{
+		"10",
+		"01",
+	})
+	b := newFromString(image.Pt(10, 10), []string{
+		"111",
+		"110",
+	})
+
+	// r goes out of the bounds of dst, a and b
+	dst := New(image.Rect(0, 0, 4, 3))
+	dst.SetBit(3, 2, On)
+	CombineTo(dst, image.Rect(1, 1, 10, 10), a, image.Pt(-2, -2), b, image.Pt(10, 10), Or)
+
+	want := newFromString(image.Pt(0, 0), []string{
+		"0000",
+		"0110",
+		"0111",
+	})
+	if err := test.Diff(want, dst); err != nil {
+		t.Errorf("CombineTo: %v", err)
+	}
+
+	// completely clipped
+	CombineTo(dst, image.Rect(1, 1, 10, 10), a, image.Pt(50, 50), b, image.Pt(10, 10), Xor)
+	if err := test.Diff(want, dst); err != nil {
+		t.Errorf("CombineTo out of a bounds should be a no-op: %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	dst := newFromString(image.Pt(0, 0), []string{
+		"1100",
+		"1100",
+		"0000",
+	})
+	src := newFromString(image.Pt(5, 5), []string{
+		"11",
+		"10",
+	})
+
+	Apply(dst, image.Rect(1, 0, 3, 2), src, image.Pt(5, 5), Xor)
+	want := newFromString(image.Pt(0, 0), []string{
+		"1010",
+		"1000",
+		"0000",
+	})
+	if err := test.Diff(want, dst); err != nil {
+		t.Errorf("Apply: %v", err)
+	}
+
+	// in-place operations don't allocate
+	allocs := testing.AllocsPerRun(10, func() {
+		Apply(dst, dst.Rect, src, image.Pt(5, 5), And)
+		Invert(dst, dst.Rect)
+	})
+	if allocs != 0 {
+		t.Errorf("want no allocations for in-place operations, got %v", allocs)
+	}
+}
+
+func TestNotInvert(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/bwgopher.png")
+	test.Check(t, err)
+	bin := NewFromImage(src)
+
+	not := Not(bin)
+	for y := bin.Rect.Min.Y; y < bin.Rect.Max.Y; y++ {
+		for x := bin.Rect.Min.X; x < bin.Rect.Max.X; x++ {
+			if not.BitAt(x, y) == bin.BitAt(x, y) {
+				t.Fatalf("want inverted pixel at (%d,%d)", x, y)
+			}
+		}
+	}
+
+	r := image.Rect(100, 100, 200, 200)
+	Invert(bin, r)
+	if err := test.Diff(not.SubImage(r), bin.SubImage(r)); err != nil {
+		t.Errorf("Invert: %v", err)
+	}
+	Invert(bin, r)
+	if err := test.Diff(src, bin); err != nil {
+		t.Errorf("double Invert: %v", err)
+	}
+}
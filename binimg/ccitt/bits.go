@@ -0,0 +1,93 @@
+package ccitt
+
+import (
+	"bufio"
+	"io"
+)
+
+// A bitWriter writes bits, most significant bit first.
+type bitWriter struct {
+	w   *bufio.Writer
+	acc uint64 // pending bits, in the n lowest bits
+	n   uint   // number of pending bits, always lower than 8
+	err error
+}
+
+// writeBits writes the n lowest bits of bits.
+func (b *bitWriter) writeBits(bits uint32, n uint) {
+	b.acc = b.acc<<n | uint64(bits)&(1<<n-1)
+	b.n += n
+	for b.n >= 8 {
+		b.n -= 8
+		if b.err == nil {
+			b.err = b.w.WriteByte(byte(b.acc >> b.n))
+		}
+	}
+}
+
+// writeCode writes code word c.
+func (b *bitWriter) writeCode(c code) {
+	b.writeBits(uint32(c.bits), uint(c.n))
+}
+
+// align writes 0 bits up to the next byte boundary.
+func (b *bitWriter) align() {
+	if b.n != 0 {
+		b.writeBits(0, 8-b.n)
+	}
+}
+
+// flush aligns and flushes the written bits to the underlying writer.
+func (b *bitWriter) flush() error {
+	b.align()
+	if b.err != nil {
+		return b.err
+	}
+	return b.w.Flush()
+}
+
+// A bitReader reads bits, most significant bit first, from a byte slice.
+type bitReader struct {
+	buf []byte
+	pos int // index of the next bit to read
+}
+
+// peek returns the next n bits, n being at most 24, without consuming them.
+// Bits past the end of buf are 0.
+func (b *bitReader) peek(n uint) uint32 {
+	var v uint32
+	i := b.pos >> 3
+	for k := i; k < i+4; k++ {
+		v <<= 8
+		if k < len(b.buf) {
+			v |= uint32(b.buf[k])
+		}
+	}
+	return v << uint(b.pos&7) >> (32 - n)
+}
+
+// skip consumes the next n bits.
+func (b *bitReader) skip(n uint) error {
+	if b.pos+int(n) > len(b.buf)*8 {
+		b.pos = len(b.buf) * 8
+		return io.ErrUnexpectedEOF
+	}
+	b.pos += int(n)
+	return nil
+}
+
+// readBit reads the next bit.
+func (b *bitReader) readBit() (uint32, error) {
+	v := b.peek(1)
+	return v, b.skip(1)
+}
+
+// align skips the bits up to the next byte boundary.
+func (b *bitReader) align() {
+	b.pos = (b.pos + 7) &^ 7
+}
+
+// remaining returns the number of bits left to read.
+func (b *bitReader) remaining() int {
+	return len(b.buf)*8 - b.pos
+}
@@ -0,0 +1,59 @@
+package ccitt
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestBitReaderWriter(t *testing.T) {
+	var buf bytes.Buffer
+	bw := bitWriter{w: bufio.NewWriter(&buf)}
+
+	// write values of all lengths from 1 to 24 bits
+	for n := uint(1); n <= 24; n++ {
+		bw.writeBits(uint32(n*0x9e3779)&(1<<n-1), n)
+	}
+	if err := bw.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != (300+7)/8 {
+		t.Fatalf("want %d bytes, got %d", (300+7)/8, buf.Len())
+	}
+
+	br := bitReader{buf: buf.Bytes()}
+	for n := uint(1); n <= 24; n++ {
+		want := uint32(n*0x9e3779) & (1<<n - 1)
+		if got := br.peek(n); got != want {
+			t.Fatalf("peek(%d): want %#x, got %#x", n, want, got)
+		}
+		if err := br.skip(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 4 padding bits remain, then bits past the end are 0
+	if br.remaining() != 4 {
+		t.Errorf("want 4 remaining bits, got %d", br.remaining())
+	}
+	if got := br.peek(24); got != 0 {
+		t.Errorf("want 0 bits past the end, got %#x", got)
+	}
+	if err := br.skip(5); err != io.ErrUnexpectedEOF {
+		t.Errorf("want io.ErrUnexpectedEOF skipping past the end, got %v", err)
+	}
+}
+
+func TestBitReaderAlign(t *testing.T) {
+	br := bitReader{buf: []byte{0xff, 0x0f}}
+	br.align()
+	if br.pos != 0 {
+		t.Errorf("want aligned reader to stay at 0, got %d", br.pos)
+	}
+	br.skip(3)
+	br.align()
+	if got := br.peek(8); got != 0x0f {
+		t.Errorf("want 0x0f after align, got %#x", got)
+	}
+}
@@ -0,0 +1,104 @@
+// Package ccitt implements the CCITT Group 3 (ITU-T T.4) and Group 4 (ITU-T
+// T.6) fax codings of binary images.
+//
+// Group 3 supports the one-dimensional (Modified Huffman) and the
+// two-dimensional (Modified READ) coding schemes, Group 4 the
+// two-dimensional Modified Modified READ coding scheme. The uncompressed mode
+// extension is not supported.
+//
+// Coded lines are made of alternating runs of white and black pixels, white
+// pixels being binimg.On pixels, and black pixels binimg.Off pixels, unless
+// Options.Invert is set.
+package ccitt
+
+import "errors"
+
+// A SubFormat is a CCITT coding scheme.
+type SubFormat int
+
+const (
+	// Group3 is the ITU-T T.4 coding, made of EOL-separated lines, coded
+	// either one or two-dimensionally depending on Options.K.
+	Group3 SubFormat = iota
+	// Group4 is the ITU-T T.6 coding, in which all lines are coded
+	// two-dimensionally.
+	Group4
+)
+
+// AutoDetectHeight can be passed as height to Decode, in order to decode
+// lines until the end of page marker (RTC in Group3, EOFB in Group4) or the
+// end of data.
+const AutoDetectHeight = -1
+
+// Options are the coding parameters.
+type Options struct {
+	// K is, for Group3, the parameter of the two-dimensional coding: each
+	// line coded one-dimensionally is followed by at most K-1 lines coded
+	// two-dimensionally. A zero K means one-dimensional coding only, with
+	// lines not tagged with their coding scheme. T.4 recommends K=2 for
+	// standard vertical resolution, and K=4 for high resolution.
+	//
+	// When decoding, only whether K is zero or not matters. K is ignored for
+	// Group4.
+	K int
+
+	// Align reports whether coded lines are byte-aligned: for Group3, fill
+	// bits are inserted before each EOL so that it ends on a byte boundary,
+	// for Group4, each coded line starts on a byte boundary.
+	Align bool
+
+	// Invert reports whether On pixels are coded as black, instead of white.
+	Invert bool
+}
+
+// A FormatError reports that the input is not valid CCITT coded data.
+type FormatError string
+
+func (e FormatError) Error() string { return "ccitt: invalid format: " + string(e) }
+
+var errInvalidSize = errors.New("ccitt: invalid image size")
+
+// changes returns dst, overwritten with the changing elements of row, that is
+// the positions of the pixels whose color is different from that of the
+// previous pixel, the imaginary pixel before the row being white. whiteOn
+// reports whether On pixels are white.
+//
+// Even indexes of a slice of changing elements are changes to black, odd
+// indexes changes to white.
+func changes(dst []int, row []byte, whiteOn bool) []int {
+	dst = dst[:0]
+	white := true
+	for x, v := range row {
+		if ((v != 0) == whiteOn) != white {
+			dst = append(dst, x)
+			white = !white
+		}
+	}
+	return dst
+}
+
+// refChanges returns b1, the first changing element of the reference line ref
+// on the right of a0 and of opposite color to white, and b2, the next
+// changing element after b1. Changing elements that do not exist are located
+// at width.
+//
+// j is the index of the first element of ref on the right of a0, as found by
+// a previous call with a lower or equal a0, it is updated.
+func refChanges(ref []int, width, a0 int, white bool, j *int) (b1, b2 int) {
+	for *j < len(ref) && ref[*j] <= a0 {
+		*j++
+	}
+	i := *j
+	// ref[i] is a change to black if i is even
+	if (i&1 == 0) != white {
+		i++
+	}
+	b1, b2 = width, width
+	if i < len(ref) {
+		b1 = ref[i]
+	}
+	if i+1 < len(ref) {
+		b2 = ref[i+1]
+	}
+	return b1, b2
+}
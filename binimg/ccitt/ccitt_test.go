@@ -0,0 +1,128 @@
+package ccitt
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/arl/imgtools/binimg"
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestChanges(t *testing.T) {
+	on, off := binimg.On.V, binimg.Off.V
+	row := []byte{off, off, on, on, on, off, on, on}
+
+	if got, want := changes(nil, row, true), []int{0, 2, 5, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changes with On white: want %v, got %v", want, got)
+	}
+	if got, want := changes(nil, row, false), []int{2, 5, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changes with On black: want %v, got %v", want, got)
+	}
+}
+
+func TestRefChanges(t *testing.T) {
+	// reference line: white 0-2, black 3-5, white 6-7, black 8-9
+	ref := []int{3, 6, 8}
+	var tests = []struct {
+		a0     int
+		white  bool
+		b1, b2 int
+	}{
+		{-1, true, 3, 6},
+		{2, true, 3, 6},
+		{3, true, 8, 10},
+		{3, false, 6, 8},
+		{5, false, 6, 8},
+		{6, false, 10, 10},
+		{9, true, 10, 10},
+	}
+	for _, tt := range tests {
+		j := 0
+		b1, b2 := refChanges(ref, 10, tt.a0, tt.white, &j)
+		if b1 != tt.b1 || b2 != tt.b2 {
+			t.Errorf("refChanges(a0:%d, white:%v): want (%d, %d), got (%d, %d)", tt.a0, tt.white, tt.b1, tt.b2, b1, b2)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var tests = []struct {
+		sf   SubFormat
+		opts *Options
+	}{
+		{Group3, nil},
+		{Group3, &Options{Align: true}},
+		{Group3, &Options{K: 1}},
+		{Group3, &Options{K: 2}},
+		{Group3, &Options{K: 4, Align: true, Invert: true}},
+		{Group4, nil},
+		{Group4, &Options{Align: true}},
+		{Group4, &Options{Invert: true}},
+	}
+
+	for _, filename := range []string{"../../testdata/bwgopher.png", "../../testdata/big.png"} {
+		src, err := test.LoadPNG(filename)
+		test.Check(t, err)
+		bin := binimg.NewFromImage(src)
+		images := []*binimg.Image{
+			bin,
+			bin.SubImage(image.Rect(3, 5, 301, 227)).(*binimg.Image),
+		}
+
+		for _, tt := range tests {
+			for _, img := range images {
+				name := fmt.Sprintf("%s %v sf=%d opts=%+v", filename, img.Rect, tt.sf, tt.opts)
+				var buf bytes.Buffer
+				test.Check(t, Encode(&buf, img, tt.sf, tt.opts))
+				w, h := img.Rect.Dx(), img.Rect.Dy()
+
+				for _, height := range []int{h, AutoDetectHeight} {
+					got, err := Decode(bytes.NewReader(buf.Bytes()), tt.sf, w, height, tt.opts)
+					if err != nil {
+						t.Fatalf("%s height=%d: %v", name, height, err)
+					}
+					if got.Rect != image.Rect(0, 0, w, h) {
+						t.Fatalf("%s height=%d: want bounds %v, got %v", name, height, image.Rect(0, 0, w, h), got.Rect)
+					}
+					// decoded images have their origin at (0, 0)
+					want := binimg.New(got.Rect)
+					for y := 0; y < h; y++ {
+						i := img.PixOffset(img.Rect.Min.X, y+img.Rect.Min.Y)
+						copy(want.Pix[y*want.Stride:], img.Pix[i:i+w])
+					}
+					if !bytes.Equal(want.Pix, got.Pix) {
+						t.Errorf("%s height=%d: decoded image differs from original", name, height)
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkEncodeGroup4(b *testing.B) {
+	src, err := test.LoadPNG("../../testdata/big.png")
+	test.CheckB(b, err)
+	bin := binimg.NewFromImage(src)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		Encode(&buf, bin, Group4, nil)
+	}
+}
+
+func BenchmarkDecodeGroup4(b *testing.B) {
+	src, err := test.LoadPNG("../../testdata/big.png")
+	test.CheckB(b, err)
+	bin := binimg.NewFromImage(src)
+	var buf bytes.Buffer
+	test.CheckB(b, Encode(&buf, bin, Group4, nil))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Decode(bytes.NewReader(buf.Bytes()), Group4, bin.Rect.Dx(), bin.Rect.Dy(), nil)
+	}
+}
@@ -0,0 +1,264 @@
+package ccitt
+
+import (
+	"image"
+	"io"
+	"io/ioutil"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+type decoder struct {
+	br       bitReader
+	sf       SubFormat
+	opts     Options
+	width    int
+	ref, cur []int // changing elements of the reference and coding lines
+}
+
+// Decode reads from r a page of lines of width pixels, coded with sub-format
+// sf, and returns it as a *binimg.Image. If opts is nil, the zero Options are
+// used.
+//
+// If height is AutoDetectHeight, lines are decoded until the end of page
+// marker or the end of data, otherwise exactly height lines are decoded, and
+// the remaining data is ignored.
+func Decode(r io.Reader, sf SubFormat, width, height int, opts *Options) (*binimg.Image, error) {
+	if width <= 0 || height < AutoDetectHeight {
+		return nil, errInvalidSize
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decoder{
+		br:    bitReader{buf: buf},
+		sf:    sf,
+		opts:  *opts,
+		width: width,
+	}
+	var pix []byte
+	if height != AutoDetectHeight {
+		pix = make([]byte, 0, width*height)
+	}
+	for y := 0; height == AutoDetectHeight || y < height; y++ {
+		more, err := d.decodeLine()
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			if height != AutoDetectHeight {
+				return nil, io.ErrUnexpectedEOF
+			}
+			break
+		}
+		pix = d.render(pix)
+	}
+	return &binimg.Image{
+		Pix:    pix,
+		Stride: width,
+		Rect:   image.Rect(0, 0, width, len(pix)/width),
+	}, nil
+}
+
+// decodeLine decodes the next line, it reports whether a line has been
+// decoded or the end of page has been reached.
+func (d *decoder) decodeLine() (bool, error) {
+	twoD := d.sf == Group4
+	// no code word starts with 8 zeros, they are either the start of an EOL,
+	// or the zero padding at the end of data.
+	if d.sf == Group4 {
+		if d.opts.Align {
+			d.br.align()
+		}
+		if d.br.peek(8) == 0 {
+			return false, nil
+		}
+	} else {
+		if d.br.peek(8) == 0 {
+			if ok, err := d.readEOL(); !ok || err != nil {
+				return false, err
+			}
+			if d.opts.K != 0 {
+				tag, err := d.br.readBit()
+				if err != nil {
+					return false, err
+				}
+				twoD = tag == 0
+			}
+			// consecutive EOLs make a RTC
+			if d.br.peek(8) == 0 {
+				return false, nil
+			}
+		} else if d.opts.K != 0 {
+			return false, FormatError("missing EOL")
+		}
+	}
+
+	d.ref, d.cur = d.cur, d.ref[:0]
+	if twoD {
+		return true, d.decode2D()
+	}
+	return true, d.decode1D()
+}
+
+// readEOL reads an EOL, preceded by any number of fill bits. It reports
+// whether an EOL has been read or the end of data has been reached.
+func (d *decoder) readEOL() (bool, error) {
+	zeros := 0
+	for d.br.remaining() > 0 {
+		if d.br.peek(1) == 1 {
+			if zeros < eolLen-1 {
+				return false, FormatError("invalid EOL")
+			}
+			return true, d.br.skip(1)
+		}
+		d.br.skip(1)
+		zeros++
+	}
+	return false, nil
+}
+
+// decode1D decodes a line made of runs of alternating colors, starting with
+// white.
+func (d *decoder) decode1D() error {
+	pos, white := 0, true
+	for pos < d.width {
+		run, err := d.readRun(white)
+		if err != nil {
+			return err
+		}
+		pos += run
+		if pos > d.width {
+			return FormatError("run exceeds line width")
+		}
+		d.addChange(pos)
+		white = !white
+	}
+	return nil
+}
+
+// decode2D decodes a line coded with respect to the reference line.
+func (d *decoder) decode2D() error {
+	a0, white := -1, true
+	j := 0 // index of the first changing element of ref on the right of a0
+	for a0 < d.width {
+		b1, b2 := refChanges(d.ref, d.width, a0, white, &j)
+		mode, err := d.decode(modeTable[:], modeBits)
+		if err != nil {
+			return err
+		}
+
+		switch mode {
+		case modePass:
+			a0 = b2
+		case modeHoriz:
+			if a0 < 0 {
+				a0 = 0
+			}
+			run1, err := d.readRun(white)
+			if err != nil {
+				return err
+			}
+			run2, err := d.readRun(!white)
+			if err != nil {
+				return err
+			}
+			a1 := a0 + run1
+			a2 := a1 + run2
+			if a2 > d.width {
+				return FormatError("run exceeds line width")
+			}
+			d.addChange(a1)
+			d.addChange(a2)
+			a0 = a2
+		default:
+			a1 := b1 + mode - modeV0
+			if a1 <= a0 || a1 > d.width {
+				return FormatError("invalid vertical mode")
+			}
+			d.addChange(a1)
+			a0, white = a1, !white
+		}
+	}
+	return nil
+}
+
+// addChange adds a changing element to the coding line. Changing elements
+// at the end of line are ignored, and two changes at the same position
+// cancel each other.
+func (d *decoder) addChange(pos int) {
+	if pos >= d.width {
+		return
+	}
+	if n := len(d.cur); n > 0 && d.cur[n-1] == pos {
+		d.cur = d.cur[:n-1]
+		return
+	}
+	d.cur = append(d.cur, pos)
+}
+
+// readRun reads the make-up and terminating codes of a run of pixels of the
+// given color, and returns the run length.
+func (d *decoder) readRun(white bool) (int, error) {
+	table := blackTable[:]
+	if white {
+		table = whiteTable[:]
+	}
+	run := 0
+	for {
+		v, err := d.decode(table, runBits)
+		if err != nil {
+			return 0, err
+		}
+		run += v
+		if v < 64 {
+			return run, nil
+		}
+		if run > d.width {
+			return 0, FormatError("run exceeds line width")
+		}
+	}
+}
+
+// decode reads the next code word with a decoding table of n bits, and
+// returns its value.
+func (d *decoder) decode(table []entry, n uint) (int, error) {
+	e := table[d.br.peek(n)]
+	if e.n == 0 {
+		if d.br.remaining() < int(n) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, FormatError("invalid code")
+	}
+	return int(e.val), d.br.skip(uint(e.n))
+}
+
+// render appends the pixels of the coding line to pix.
+func (d *decoder) render(pix []byte) []byte {
+	white, black := binimg.On.V, binimg.Off.V
+	if d.opts.Invert {
+		white, black = black, white
+	}
+	prev, v := 0, white
+	for i := 0; i <= len(d.cur); i++ {
+		next := d.width
+		if i < len(d.cur) {
+			next = d.cur[i]
+		}
+		for x := prev; x < next; x++ {
+			pix = append(pix, v)
+		}
+		prev = next
+		if v == white {
+			v = black
+		} else {
+			v = white
+		}
+	}
+	return pix
+}
@@ -0,0 +1,112 @@
+package ccitt
+
+import (
+	"bytes"
+	"image"
+	"io"
+	"testing"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+func TestDecode(t *testing.T) {
+	want := newTestImage()
+	for _, tt := range encodeTests {
+		for _, height := range []int{2, AutoDetectHeight} {
+			got, err := Decode(bytes.NewReader(bitsToBytes(tt.bits)), tt.sf, 8, height, tt.opts)
+			if err != nil {
+				t.Errorf("%s height=%d: %v", tt.name, height, err)
+				continue
+			}
+			if got.Rect != want.Rect || !bytes.Equal(got.Pix, want.Pix) {
+				t.Errorf("%s height=%d: want %v, got %v", tt.name, height, want.Pix, got.Pix)
+			}
+		}
+	}
+}
+
+func TestDecodeGroup3Variants(t *testing.T) {
+	want := newTestImage()
+	var tests = []struct {
+		name   string
+		bits   string
+		height int
+	}{
+		{
+			"no EOL",
+			"10011 0111 10 1000",
+			AutoDetectHeight,
+		},
+		{
+			"extra fill bits",
+			"0000000000" + testEOL + "10011 " +
+				"000000000000000" + testEOL + "0111 10 1000",
+			AutoDetectHeight,
+		},
+		{
+			"ignored trailing data",
+			testEOL + "10011 " + testEOL + "0111 10 1000 " + "1111",
+			2,
+		},
+	}
+	for _, tt := range tests {
+		got, err := Decode(bytes.NewReader(bitsToBytes(tt.bits)), Group3, 8, tt.height, nil)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got.Rect != want.Rect || !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("%s: want %v, got %v", tt.name, want.Pix, got.Pix)
+		}
+	}
+}
+
+func TestDecodeEmpty(t *testing.T) {
+	for _, sf := range []SubFormat{Group3, Group4} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, binimg.New(image.Rect(0, 0, 10, 0)), sf, nil); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Decode(&buf, sf, 10, AutoDetectHeight, nil)
+		if err != nil {
+			t.Fatalf("sf=%d: %v", sf, err)
+		}
+		if got.Rect.Dx() != 10 || got.Rect.Dy() != 0 {
+			t.Errorf("sf=%d: want 10x0 image, got %v", sf, got.Rect)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var tests = []struct {
+		name          string
+		sf            SubFormat
+		opts          *Options
+		width, height int
+		bits          string
+		want          error
+	}{
+		{"invalid width", Group4, nil, 0, 1, "1", errInvalidSize},
+		{"invalid height", Group4, nil, 8, -2, "1", errInvalidSize},
+		{"truncated run", Group3, nil, 8, 1, testEOL + "0000", io.ErrUnexpectedEOF},
+		{"missing lines", Group4, nil, 8, 3, "1 1", io.ErrUnexpectedEOF},
+		{"early RTC", Group3, nil, 8, 2, testEOL + "10011 " + testRTC, io.ErrUnexpectedEOF},
+		{"missing EOL", Group3, &Options{K: 2}, 8, 1, "1 10011", FormatError("")},
+		{"short EOL", Group3, nil, 8, 1, "000000001 10011", FormatError("")},
+		{"run too long", Group3, nil, 8, 1, testEOL + "10100", FormatError("")},
+		{"invalid vertical mode", Group4, nil, 8, 1, "0000011", FormatError("")},
+		{"uncompressed mode", Group4, nil, 8, 1, "0000001111 1111", FormatError("")},
+	}
+	for _, tt := range tests {
+		_, err := Decode(bytes.NewReader(bitsToBytes(tt.bits)), tt.sf, tt.width, tt.height, tt.opts)
+		if _, ok := tt.want.(FormatError); ok {
+			if _, ok := err.(FormatError); !ok {
+				t.Errorf("%s: want FormatError, got %v", tt.name, err)
+			}
+			continue
+		}
+		if err != tt.want {
+			t.Errorf("%s: want %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package ccitt
+
+// Code words, as listed in ITU-T T.4, written most significant bit first.
+var (
+	// whiteTerm and blackTerm are the terminating codes, indexed by run
+	// length, from 0 to 63.
+	whiteTerm = [64]string{
+		"00110101", "000111", "0111", "1000", "1011", "1100", "1110", "1111",
+		"10011", "10100", "00111", "01000", "001000", "000011", "110100", "110101",
+		"101010", "101011", "0100111", "0001100", "0001000", "0010111", "0000011", "0000100",
+		"0101000", "0101011", "0010011", "0100100", "0011000", "00000010", "00000011", "00011010",
+		"00011011", "00010010", "00010011", "00010100", "00010101", "00010110", "00010111", "00101000",
+		"00101001", "00101010", "00101011", "00101100", "00101101", "00000100", "00000101", "00001010",
+		"00001011", "01010010", "01010011", "01010100", "01010101", "00100100", "00100101", "01011000",
+		"01011001", "01011010", "01011011", "01001010", "01001011", "00110010", "00110011", "00110100",
+	}
+	blackTerm = [64]string{
+		"0000110111", "010", "11", "10", "011", "0011", "0010", "00011",
+		"000101", "000100", "0000100", "0000101", "0000111", "00000100", "00000111", "000011000",
+		"0000010111", "0000011000", "0000001000", "00001100111", "00001101000", "00001101100", "00000110111", "00000101000",
+		"00000010111", "00000011000", "000011001010", "000011001011", "000011001100", "000011001101", "000001101000", "000001101001",
+		"000001101010", "000001101011", "000011010010", "000011010011", "000011010100", "000011010101", "000011010110", "000011010111",
+		"000001101100", "000001101101", "000011011010", "000011011011", "000001010100", "000001010101", "000001010110", "000001010111",
+		"000001100100", "000001100101", "000001010010", "000001010011", "000000100100", "000000110111", "000000111000", "000000100111",
+		"000000101000", "000001011000", "000001011001", "000000101011", "000000101100", "000001011010", "000001100110", "000001100111",
+	}
+
+	// whiteMakeup and blackMakeup are the make-up codes of run lengths 64 to
+	// 1728, by steps of 64.
+	whiteMakeup = [27]string{
+		"11011", "10010", "010111", "0110111", "00110110", "00110111", "01100100", "01100101",
+		"01101000", "01100111", "011001100", "011001101", "011010010", "011010011", "011010100", "011010101",
+		"011010110", "011010111", "011011000", "011011001", "011011010", "011011011", "010011000", "010011001",
+		"010011010", "011000", "010011011",
+	}
+	blackMakeup = [27]string{
+		"0000001111", "000011001000", "000011001001", "000001011011", "000000110011", "000000110100", "000000110101", "0000001101100",
+		"0000001101101", "0000001001010", "0000001001011", "0000001001100", "0000001001101", "0000001110010", "0000001110011", "0000001110100",
+		"0000001110101", "0000001110110", "0000001110111", "0000001010010", "0000001010011", "0000001010100", "0000001010101", "0000001011010",
+		"0000001011011", "0000001100100", "0000001100101",
+	}
+
+	// extMakeup are the make-up codes, common to both colors, of run lengths
+	// 1792 to 2560, by steps of 64.
+	extMakeup = [13]string{
+		"00000001000", "00000001100", "00000001101", "000000010010", "000000010011", "000000010100", "000000010101", "000000010110",
+		"000000010111", "000000011100", "000000011101", "000000011110", "000000011111",
+	}
+
+	// modes are the two-dimensional coding mode codes, indexed by mode.
+	modes = [...]string{
+		modeVL3:   "0000010",
+		modeVL2:   "000010",
+		modeVL1:   "010",
+		modeV0:    "1",
+		modeVR1:   "011",
+		modeVR2:   "000011",
+		modeVR3:   "0000011",
+		modePass:  "0001",
+		modeHoriz: "001",
+	}
+)
+
+// Two-dimensional coding modes. Vertical modes are ordered so that modeV0+d
+// is the vertical mode in which a1 is d pixels on the right of b1.
+const (
+	modeVL3 = iota
+	modeVL2
+	modeVL1
+	modeV0
+	modeVR1
+	modeVR2
+	modeVR3
+	modePass
+	modeHoriz
+)
+
+const (
+	// eol is the end-of-line code, of eolLen bits.
+	eol    = 0x001
+	eolLen = 12
+
+	// maxMakeup is the longest run length coded with a single make-up code.
+	maxMakeup = 2560
+
+	// runBits and modeBits are the lengths of, respectively, the longest run
+	// code and the longest mode code.
+	runBits  = 13
+	modeBits = 7
+)
+
+// A code is a code word.
+type code struct {
+	bits uint16 // code word, right-aligned
+	n    uint8  // length in bits
+}
+
+// An entry is an element of a decoding table, indexed by the next bits of
+// the input, holding the decoded value and the length of its code word. An
+// entry of length 0 means that the bits do not start with a valid code word.
+type entry struct {
+	val int16
+	n   uint8
+}
+
+var (
+	// whiteCodes and blackCodes are the terminating, then make-up codes of
+	// each color, indexed by run length for terminating codes, and by run
+	// length / 64 + 63 for make-up codes.
+	whiteCodes, blackCodes [64 + maxMakeup/64]code
+	modeCodes              [len(modes)]code
+
+	// decoding tables
+	whiteTable, blackTable [1 << runBits]entry
+	modeTable              [1 << modeBits]entry
+)
+
+func init() {
+	addRun := func(codes []code, table []entry, run int, s string) {
+		c := parseCode(s)
+		i := run
+		if run >= 64 {
+			i = run/64 + 63
+		}
+		codes[i] = c
+		addEntry(table, runBits, c, run)
+	}
+	for run := 0; run < 64; run++ {
+		addRun(whiteCodes[:], whiteTable[:], run, whiteTerm[run])
+		addRun(blackCodes[:], blackTable[:], run, blackTerm[run])
+	}
+	for i := range whiteMakeup {
+		run := (i + 1) * 64
+		addRun(whiteCodes[:], whiteTable[:], run, whiteMakeup[i])
+		addRun(blackCodes[:], blackTable[:], run, blackMakeup[i])
+	}
+	for i, s := range extMakeup {
+		run := 1792 + i*64
+		addRun(whiteCodes[:], whiteTable[:], run, s)
+		addRun(blackCodes[:], blackTable[:], run, s)
+	}
+	for mode, s := range modes {
+		modeCodes[mode] = parseCode(s)
+		addEntry(modeTable[:], modeBits, modeCodes[mode], mode)
+	}
+}
+
+// parseCode parses a code word written in binary.
+func parseCode(s string) code {
+	var c code
+	for i := 0; i < len(s); i++ {
+		c.bits = c.bits<<1 | uint16(s[i]-'0')
+	}
+	c.n = uint8(len(s))
+	return c
+}
+
+// addEntry adds the value v of code c to the decoding table of n bits.
+func addEntry(table []entry, n uint, c code, v int) {
+	shift := n - uint(c.n)
+	first := int(c.bits) << shift
+	for i := first; i < first+1<<shift; i++ {
+		table[i] = entry{val: int16(v), n: c.n}
+	}
+}
@@ -0,0 +1,85 @@
+package ccitt
+
+import (
+	"strings"
+	"testing"
+)
+
+// kraftSum returns the sum of 2^(16-len) over the code words of codes.
+func kraftSum(codes []string) int {
+	sum := 0
+	for _, c := range codes {
+		sum += 1 << uint(16-len(c))
+	}
+	return sum
+}
+
+func isPrefixFree(codes []string) bool {
+	for i, c0 := range codes {
+		for j, c1 := range codes {
+			if i != j && strings.HasPrefix(c1, c0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestCodeTables(t *testing.T) {
+	white := append(append(whiteTerm[:], whiteMakeup[:]...), extMakeup[:]...)
+	black := append(append(blackTerm[:], blackMakeup[:]...), extMakeup[:]...)
+
+	// run codes are complete prefix codes, except for the code words starting
+	// with 8 zeros, which are reserved to EOL.
+	for _, tt := range []struct {
+		name  string
+		codes []string
+	}{
+		{"white", white},
+		{"black", black},
+	} {
+		if !isPrefixFree(tt.codes) {
+			t.Errorf("%s codes are not prefix-free", tt.name)
+		}
+		if got, want := kraftSum(tt.codes), 1<<16-1<<8; got != want {
+			t.Errorf("%s codes Kraft sum: want %d, got %d", tt.name, want, got)
+		}
+	}
+	if !isPrefixFree(modes[:]) {
+		t.Errorf("mode codes are not prefix-free")
+	}
+}
+
+func TestDecodingTables(t *testing.T) {
+	for run := 0; run < 64+maxMakeup; run++ {
+		if run >= 64 && run%64 != 0 {
+			continue
+		}
+		i := run
+		if run >= 64 {
+			i = run/64 + 63
+		}
+		for _, tt := range []struct {
+			codes *[64 + maxMakeup/64]code
+			table *[1 << runBits]entry
+		}{
+			{&whiteCodes, &whiteTable},
+			{&blackCodes, &blackTable},
+		} {
+			c := tt.codes[i]
+			e := tt.table[uint(c.bits)<<(runBits-uint(c.n))]
+			if int(e.val) != run || e.n != c.n {
+				t.Errorf("run %d: want entry {%d %d}, got %v", run, run, c.n, e)
+			}
+		}
+	}
+	for mode, c := range modeCodes {
+		e := modeTable[uint(c.bits)<<(modeBits-uint(c.n))]
+		if int(e.val) != mode || e.n != c.n {
+			t.Errorf("mode %d: want entry {%d %d}, got %v", mode, mode, c.n, e)
+		}
+	}
+	if e := whiteTable[0]; e.n != 0 {
+		t.Errorf("want no code word made of zeros, got %v", e)
+	}
+}
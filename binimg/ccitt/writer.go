@@ -0,0 +1,160 @@
+package ccitt
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+type encoder struct {
+	bw       bitWriter
+	sf       SubFormat
+	opts     Options
+	width    int
+	ref, cur []int // changing elements of the reference and coding lines
+}
+
+// Encode writes the image m to w, coded with sub-format sf. If opts is nil,
+// the zero Options are used.
+//
+// Group3 pages start with an EOL and end with a RTC, Group4 pages end with an
+// EOFB, byte-aligned if lines are. The last byte is padded with 0 bits.
+func Encode(w io.Writer, m *binimg.Image, sf SubFormat, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	e := &encoder{
+		bw:    bitWriter{w: bufio.NewWriter(w)},
+		sf:    sf,
+		opts:  *opts,
+		width: m.Rect.Dx(),
+	}
+	for y := m.Rect.Min.Y; y < m.Rect.Max.Y; y++ {
+		i := m.PixOffset(m.Rect.Min.X, y)
+		e.ref, e.cur = e.cur, changes(e.ref, m.Pix[i:i+e.width], !e.opts.Invert)
+		e.encodeLine(y - m.Rect.Min.Y)
+	}
+	e.endPage()
+	return e.bw.flush()
+}
+
+// encodeLine encodes the line at index y of the page.
+func (e *encoder) encodeLine(y int) {
+	if e.sf == Group4 {
+		if e.opts.Align {
+			e.bw.align()
+		}
+		e.encode2D()
+		return
+	}
+
+	e.writeEOL()
+	if e.opts.K == 0 {
+		e.encode1D()
+		return
+	}
+	// the tag bit following the EOL is 1 for one-dimensionally coded lines
+	if y%e.opts.K == 0 {
+		e.bw.writeBits(1, 1)
+		e.encode1D()
+	} else {
+		e.bw.writeBits(0, 1)
+		e.encode2D()
+	}
+}
+
+// endPage writes the end of page marker.
+func (e *encoder) endPage() {
+	if e.sf == Group4 {
+		// EOFB
+		if e.opts.Align {
+			e.bw.align()
+		}
+		e.bw.writeBits(eol, eolLen)
+		e.bw.writeBits(eol, eolLen)
+		return
+	}
+	// RTC
+	for i := 0; i < 6; i++ {
+		e.writeEOL()
+		if e.opts.K != 0 {
+			e.bw.writeBits(1, 1)
+		}
+	}
+}
+
+// writeEOL writes an EOL, preceded by the fill bits making it end on a byte
+// boundary if lines are aligned.
+func (e *encoder) writeEOL() {
+	if e.opts.Align {
+		if fill := (8 - (e.bw.n+eolLen)%8) % 8; fill != 0 {
+			e.bw.writeBits(0, fill)
+		}
+	}
+	e.bw.writeBits(eol, eolLen)
+}
+
+// encode1D codes the current line as a sequence of runs of alternating
+// colors, starting with white.
+func (e *encoder) encode1D() {
+	prev, white := 0, true
+	for _, c := range e.cur {
+		e.writeRun(c-prev, white)
+		prev, white = c, !white
+	}
+	e.writeRun(e.width-prev, white)
+}
+
+// encode2D codes the current line with respect to the reference line.
+func (e *encoder) encode2D() {
+	a0, white := -1, true
+	j, k := 0, 0 // indexes of the changing elements on the right of a0
+	for a0 < e.width {
+		for k < len(e.cur) && e.cur[k] <= a0 {
+			k++
+		}
+		a1, a2 := e.width, e.width
+		if k < len(e.cur) {
+			a1 = e.cur[k]
+		}
+		if k+1 < len(e.cur) {
+			a2 = e.cur[k+1]
+		}
+		b1, b2 := refChanges(e.ref, e.width, a0, white, &j)
+
+		switch d := a1 - b1; {
+		case b2 < a1:
+			e.bw.writeCode(modeCodes[modePass])
+			a0 = b2
+		case -3 <= d && d <= 3:
+			e.bw.writeCode(modeCodes[modeV0+d])
+			a0, white = a1, !white
+		default:
+			e.bw.writeCode(modeCodes[modeHoriz])
+			if a0 < 0 {
+				a0 = 0
+			}
+			e.writeRun(a1-a0, white)
+			e.writeRun(a2-a1, !white)
+			a0 = a2
+		}
+	}
+}
+
+// writeRun writes the codes of a run of pixels of the given color.
+func (e *encoder) writeRun(run int, white bool) {
+	codes := &blackCodes
+	if white {
+		codes = &whiteCodes
+	}
+	for run >= maxMakeup+64 {
+		e.bw.writeCode(codes[maxMakeup/64+63])
+		run -= maxMakeup
+	}
+	if run >= 64 {
+		e.bw.writeCode(codes[run/64+63])
+		run %= 64
+	}
+	e.bw.writeCode(codes[run])
+}
@@ -0,0 +1,91 @@
+package ccitt
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/arl/imgtools/binimg"
+	"github.com/arl/imgtools/internal/test"
+)
+
+// bitsToBytes packs a string of '0' and '1', ignoring spaces, into bytes,
+// most significant bit first, padding the last byte with 0 bits.
+func bitsToBytes(s string) []byte {
+	s = strings.Replace(s, " ", "", -1)
+	buf := make([]byte, (len(s)+7)/8)
+	for i := 0; i < len(s); i++ {
+		if s[i] == '1' {
+			buf[i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+	return buf
+}
+
+// newTestImage returns a 8x2 image, whose first row is white and second row
+// is made of 2 white, 3 black and 3 white pixels.
+func newTestImage() *binimg.Image {
+	img := binimg.New(image.Rect(0, 0, 8, 2))
+	img.SetRect(img.Rect, binimg.On)
+	img.SetRect(image.Rect(2, 1, 5, 2), binimg.Off)
+	return img
+}
+
+const (
+	testEOL = "000000000001 "
+	testRTC = testEOL + testEOL + testEOL + testEOL + testEOL + testEOL
+)
+
+var encodeTests = []struct {
+	name string
+	sf   SubFormat
+	opts *Options
+	bits string
+}{
+	{
+		"Group3 1D",
+		Group3, nil,
+		testEOL + "10011 " + // white 8
+			testEOL + "0111 10 1000 " + // white 2, black 3, white 3
+			testRTC,
+	},
+	{
+		"Group3 1D aligned",
+		Group3, &Options{Align: true},
+		"0000" + testEOL + "10011 " +
+			"0000000" + testEOL + "0111 10 1000 " +
+			"00" + testEOL + "0000" + testEOL + "0000" + testEOL + "0000" + testEOL + "0000" + testEOL + "0000" + testEOL,
+	},
+	{
+		"Group3 2D",
+		Group3, &Options{K: 2},
+		testEOL + "1 10011 " + // 1D: white 8
+			testEOL + "0 001 0111 10 1 " + // 2D: H white 2 black 3, V0
+			strings.Replace(testRTC, " ", "1 ", -1),
+	},
+	{
+		"Group4",
+		Group4, nil,
+		"1 " + // V0
+			"001 0111 10 1 " + // H white 2 black 3, V0
+			testEOL + testEOL,
+	},
+	{
+		"Group4 inverted",
+		Group4, &Options{Invert: true},
+		"001 00110101 000101 " + // H white 0 black 8
+			"1 001 11 1000 1 " + // V0, H black 2 white 3, V0
+			testEOL + testEOL,
+	},
+}
+
+func TestEncode(t *testing.T) {
+	for _, tt := range encodeTests {
+		var buf bytes.Buffer
+		test.Check(t, Encode(&buf, newTestImage(), tt.sf, tt.opts))
+		if want := bitsToBytes(tt.bits); !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("%s: want %08b, got %08b", tt.name, want, buf.Bytes())
+		}
+	}
+}
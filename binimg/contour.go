@@ -0,0 +1,270 @@
+package binimg
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+)
+
+// A Contour is the border of a connected component of On pixels, or of a
+// hole in such a component.
+type Contour struct {
+	// Points holds the border pixels, in the order they are followed. For
+	// outer borders, the pixels are followed counterclockwise, the y axis
+	// pointing down, and clockwise for hole borders.
+	Points []image.Point
+	// Hole is true if the contour is the border of a hole, i.e an Off
+	// region surrounded by On pixels.
+	Hole bool
+	// Parent is the index of the contour directly surrounding this one, -1
+	// for outer contours that are not surrounded by any other contour. The
+	// parent of a hole is the outer contour of the component it belongs to.
+	Parent int
+}
+
+// neighbour offsets, in clockwise order (the y axis pointing down), starting
+// from the east neighbour.
+var neighbours = [8]image.Point{
+	{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1},
+}
+
+const (
+	east = 0
+	west = 4
+)
+
+// Contours returns the outer and hole borders of the 8-connected components
+// of the On pixels of b, with their hierarchy.
+//
+// Contours are found with the border following algorithm of Suzuki and Abe,
+// and are returned in the order of their first pixel, in raster order.
+// Components made of a single pixel have a single-point contour.
+func Contours(b *Image) []Contour {
+	w, h := b.Rect.Dx(), b.Rect.Dy()
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+
+	// copy b in a frame of Off pixels. Border following marks the
+	// followed pixels with the number of their border.
+	pw := w + 2
+	f := make([]int32, pw*(h+2))
+	for y := 0; y < h; y++ {
+		i := b.PixOffset(b.Rect.Min.X, b.Rect.Min.Y+y)
+		for x, v := range b.Pix[i : i+w] {
+			if v != Off.V {
+				f[(y+1)*pw+x+1] = 1
+			}
+		}
+	}
+	var offs [8]int
+	for d, n := range neighbours {
+		offs[d] = n.Y*pw + n.X
+	}
+	point := func(i int) image.Point {
+		return image.Pt(i%pw-1+b.Rect.Min.X, i/pw-1+b.Rect.Min.Y)
+	}
+
+	// borders[n] is the contour of border number n. The frame is the
+	// border 1, considered as a hole, borders of contours starting at 2.
+	type border struct {
+		hole   bool
+		parent int32
+	}
+	borders := []border{{}, {hole: true}}
+	var contours []Contour
+
+	nbd := int32(1)
+	for y := 1; y <= h; y++ {
+		lnbd := int32(1)
+		for x := 1; x <= w; x++ {
+			i := y*pw + x
+			v := f[i]
+			if v == 0 {
+				continue
+			}
+
+			var (
+				from int // direction of the neighbour the border is followed from
+				hole bool
+			)
+			switch {
+			case v == 1 && f[i-1] == 0:
+				from = west
+			case v >= 1 && f[i+1] == 0:
+				from, hole = east, true
+				if v > 1 {
+					lnbd = v
+				}
+			default:
+				if v != 1 {
+					lnbd = abs32(v)
+				}
+				continue
+			}
+
+			// the parent of the new border depends on its type and the
+			// type of the last border met on this line
+			nbd++
+			parent := lnbd
+			if hole == borders[lnbd].hole {
+				parent = borders[lnbd].parent
+			}
+			borders = append(borders, border{hole, parent})
+			contours = append(contours, Contour{
+				Points: followBorder(f, i, from, nbd, &offs, point),
+				Hole:   hole,
+				Parent: int(parent) - 2,
+			})
+			if f[i] != 1 {
+				lnbd = abs32(f[i])
+			}
+		}
+	}
+	return contours
+}
+
+// followBorder follows the border starting at pixel i of f, marking its
+// pixels with nbd, and returns its points. from is the direction of the Off
+// neighbour of i from which the border is followed.
+func followBorder(f []int32, i, from int, nbd int32, offs *[8]int, point func(int) image.Point) []image.Point {
+	// look clockwise for the first On neighbour
+	dir1 := -1
+	for k := 0; k < 8; k++ {
+		d := (from + k) % 8
+		if f[i+offs[d]] != 0 {
+			dir1 = d
+			break
+		}
+	}
+	if dir1 < 0 {
+		// isolated pixel
+		f[i] = -nbd
+		return []image.Point{point(i)}
+	}
+
+	var points []image.Point
+	i1 := i + offs[dir1]
+	prev, cur := dir1, i // direction of the previous pixel, current pixel
+	for {
+		// look counterclockwise for the next On neighbour, starting after
+		// the previous pixel
+		eastOff := false
+		d := prev
+		for k := 0; k < 8; k++ {
+			d = (d + 7) % 8
+			if f[cur+offs[d]] != 0 {
+				break
+			}
+			if d == east {
+				eastOff = true
+			}
+		}
+		if eastOff {
+			f[cur] = -nbd
+		} else if f[cur] == 1 {
+			f[cur] = nbd
+		}
+		points = append(points, point(cur))
+
+		next := cur + offs[d]
+		if next == i && cur == i1 {
+			return points
+		}
+		prev, cur = (d+4)%8, next
+	}
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Simplify returns the simplification of the closed polygon pts with the
+// Douglas-Peucker algorithm: the returned polygon is made of a subset of pts
+// such that no point of pts is farther than epsilon from it.
+func Simplify(pts []image.Point, epsilon float64) []image.Point {
+	n := len(pts)
+	if n < 3 {
+		return append([]image.Point(nil), pts...)
+	}
+
+	// split the polygon in 2 polylines, at the point the farthest from the
+	// first one.
+	far, dmax := 0, -1.0
+	for i, p := range pts {
+		if d := dist(p, pts[0]); d > dmax {
+			far, dmax = i, d
+		}
+	}
+
+	keep := make([]bool, n+1)
+	keep[0], keep[far], keep[n] = true, true, true
+	at := func(i int) image.Point { return pts[i%n] }
+
+	// simplify each polyline, iteratively to support long contours
+	stack := [][2]int{{0, far}, {far, n}}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		first, last := s[0], s[1]
+		idx, dmax := -1, epsilon
+		for i := first + 1; i < last; i++ {
+			if d := segmentDist(at(i), at(first), at(last)); d > dmax {
+				idx, dmax = i, d
+			}
+		}
+		if idx >= 0 {
+			keep[idx] = true
+			stack = append(stack, [2]int{first, idx}, [2]int{idx, last})
+		}
+	}
+
+	var simplified []image.Point
+	for i := 0; i < n; i++ {
+		if keep[i] {
+			simplified = append(simplified, pts[i])
+		}
+	}
+	return simplified
+}
+
+// dist returns the euclidean distance between p and q.
+func dist(p, q image.Point) float64 {
+	return math.Hypot(float64(p.X-q.X), float64(p.Y-q.Y))
+}
+
+// segmentDist returns the distance between p and the segment [a, b].
+func segmentDist(p, a, b image.Point) float64 {
+	dx, dy := float64(b.X-a.X), float64(b.Y-a.Y)
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return dist(p, a)
+	}
+	t := (float64(p.X-a.X)*dx + float64(p.Y-a.Y)*dy) / l2
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(float64(p.X-a.X)-t*dx, float64(p.Y-a.Y)-t*dy)
+}
+
+// PathData returns the SVG path data of the closed polygon pts, suitable for
+// the d attribute of an SVG path element.
+//
+// Pixel coordinates are translated by (0.5, 0.5), so that the path goes
+// through the centers of the pixels.
+func PathData(pts []image.Point) string {
+	var sb strings.Builder
+	for i, p := range pts {
+		cmd := "L"
+		if i == 0 {
+			cmd = "M"
+		}
+		fmt.Fprintf(&sb, "%s%g %g ", cmd, float64(p.X)+0.5, float64(p.Y)+0.5)
+	}
+	if len(pts) > 0 {
+		sb.WriteString("Z")
+	}
+	return sb.String()
+}
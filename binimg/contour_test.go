@@ -0,0 +1,143 @@
+package binimg
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestContoursSquare(t *testing.T) {
+	src := newFromString(image.Pt(-1, 2), []string{
+		"00000",
+		"01110",
+		"01110",
+		"01110",
+		"00000",
+	})
+
+	contours := Contours(src)
+	if len(contours) != 1 {
+		t.Fatalf("want 1 contour, got %d: %v", len(contours), contours)
+	}
+	want := Contour{
+		Points: []image.Point{
+			{0, 3}, {0, 4}, {0, 5}, {1, 5}, {2, 5}, {2, 4}, {2, 3}, {1, 3},
+		},
+		Hole:   false,
+		Parent: -1,
+	}
+	if !reflect.DeepEqual(contours[0], want) {
+		t.Errorf("want contour %v, got %v", want, contours[0])
+	}
+}
+
+func TestContoursHierarchy(t *testing.T) {
+	src := newFromString(image.Pt(0, 0), []string{
+		"00000000001",
+		"01111111100",
+		"01000000100",
+		"01011100100",
+		"01010100100",
+		"01011100100",
+		"01000000100",
+		"01111111100",
+		"00000000001",
+	})
+
+	var want = []struct {
+		hole   bool
+		parent int
+		npts   int
+	}{
+		{false, -1, 1}, // isolated top-right pixel
+		{false, -1, 26},
+		{true, 1, 22},
+		{false, 2, 8},
+		{true, 3, 4},
+		{false, -1, 1}, // isolated bottom-right pixel
+	}
+
+	contours := Contours(src)
+	if len(contours) != len(want) {
+		t.Fatalf("want %d contours, got %d: %v", len(want), len(contours), contours)
+	}
+	for i, w := range want {
+		c := contours[i]
+		if c.Hole != w.hole || c.Parent != w.parent || len(c.Points) != w.npts {
+			t.Errorf("contour %d: want hole=%v parent=%d with %d points, got hole=%v parent=%d with %d points",
+				i, w.hole, w.parent, w.npts, c.Hole, c.Parent, len(c.Points))
+		}
+		// all points are On pixels
+		for _, p := range c.Points {
+			if src.BitAt(p.X, p.Y) != On {
+				t.Errorf("contour %d: point %v is not an On pixel", i, p)
+			}
+		}
+	}
+}
+
+func TestContoursGopher(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/bwgopher.png")
+	test.Check(t, err)
+	bin := NewFromImage(src)
+
+	// each outer contour is the contour of a 8-connected component
+	l := Label(bin, Connectivity8)
+	var outer int
+	for _, c := range Contours(bin) {
+		if c.Hole {
+			continue
+		}
+		outer++
+		label := l.LabelAt(c.Points[0].X, c.Points[0].Y)
+		for _, p := range c.Points {
+			if l.LabelAt(p.X, p.Y) != label {
+				t.Fatalf("contour crosses components %d and %d", label, l.LabelAt(p.X, p.Y))
+			}
+		}
+	}
+	if outer != len(l.Components) {
+		t.Errorf("want %d outer contours, got %d", len(l.Components), outer)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	// square contour with points on its edges
+	square := []image.Point{
+		{0, 0}, {0, 1}, {0, 2}, {0, 3}, {1, 3}, {2, 3}, {3, 3}, {3, 2}, {3, 1}, {3, 0}, {2, 0}, {1, 0},
+	}
+	want := []image.Point{{0, 0}, {0, 3}, {3, 3}, {3, 0}}
+	if got := Simplify(square, 0.5); !reflect.DeepEqual(got, want) {
+		t.Errorf("want simplified square %v, got %v", want, got)
+	}
+
+	// with a small bump, kept or not depending on epsilon
+	bumped := []image.Point{
+		{0, 0}, {0, 5}, {5, 5}, {5, 3}, {6, 2}, {5, 1}, {5, 0},
+	}
+	if got := Simplify(bumped, 2); len(got) != 4 {
+		t.Errorf("want bump removed with epsilon 2, got %v", got)
+	}
+	if got := Simplify(bumped, 0.5); len(got) != 6 || got[4] != image.Pt(6, 2) {
+		t.Errorf("want bump kept with epsilon 0.5, got %v", got)
+	}
+
+	// tiny polygons are returned as-is
+	line := []image.Point{{0, 0}, {3, 3}}
+	if got := Simplify(line, 10); !reflect.DeepEqual(got, line) {
+		t.Errorf("want 2 points polygon unchanged, got %v", got)
+	}
+}
+
+func TestPathData(t *testing.T) {
+	got := PathData([]image.Point{{0, 0}, {0, 3}, {3, 3}})
+	want := "M0.5 0.5 L0.5 3.5 L3.5 3.5 Z"
+	if got != want {
+		t.Errorf("want path data %q, got %q", want, got)
+	}
+	if got := PathData(nil); got != "" {
+		t.Errorf("want empty path data, got %q", got)
+	}
+}
@@ -0,0 +1,269 @@
+package binimg
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// A DistanceMetric is the metric of a distance transform.
+type DistanceMetric int
+
+// Distance metrics.
+const (
+	// Euclidean is the exact Euclidean distance, computed with the linear
+	// time algorithm of Felzenszwalb and Huttenlocher.
+	Euclidean DistanceMetric = iota
+	// Chamfer34 approximates the Euclidean distance with a 3x3 chamfer mask,
+	// of weight 3 for edge neighbours and 4 for corner neighbours.
+	Chamfer34
+	// Chamfer5711 approximates the Euclidean distance with a 5x5 chamfer
+	// mask, of weight 5 for edge neighbours, 7 for corner neighbours and 11
+	// for neighbours a knight's move away.
+	Chamfer5711
+)
+
+// A DistanceMap holds, for each pixel of a binary image, the distance to the
+// nearest feature pixel, i.e the nearest pixel of a given Bit.
+type DistanceMap struct {
+	// Dist holds the distance of each pixel, in pixel units. The distance of
+	// the pixel at (x, y) is at Dist[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Dist []float32
+	// Nearest holds, if requested, the coordinates of the nearest feature
+	// pixel of each pixel, laid out as Dist. It is nil otherwise, or if the
+	// image has no feature pixel.
+	Nearest []image.Point
+	// Stride is the Dist and Nearest stride between vertically adjacent
+	// pixels.
+	Stride int
+	// Rect is the distance map bounds.
+	Rect image.Rectangle
+}
+
+// DistanceAt returns the distance of the pixel at (x, y). Pixels out of the
+// map bounds, and all pixels of images without feature pixels, are infinitely
+// far.
+func (m *DistanceMap) DistanceAt(x, y int) float32 {
+	if !(image.Point{x, y}.In(m.Rect)) {
+		return float32(math.Inf(1))
+	}
+	return m.Dist[(y-m.Rect.Min.Y)*m.Stride+(x-m.Rect.Min.X)]
+}
+
+// NearestAt returns the coordinates of the nearest feature pixel of the pixel
+// at (x, y). ok is false if Nearest is nil or (x, y) is out of the map bounds.
+func (m *DistanceMap) NearestAt(x, y int) (p image.Point, ok bool) {
+	if m.Nearest == nil || !(image.Point{x, y}.In(m.Rect)) {
+		return image.Point{}, false
+	}
+	return m.Nearest[(y-m.Rect.Min.Y)*m.Stride+(x-m.Rect.Min.X)], true
+}
+
+// Gray16 returns a gray image of the distances multiplied by scale, rounded
+// and clamped to 0xffff.
+func (m *DistanceMap) Gray16(scale float64) *image.Gray16 {
+	dst := image.NewGray16(m.Rect)
+	for y := m.Rect.Min.Y; y < m.Rect.Max.Y; y++ {
+		i := (y - m.Rect.Min.Y) * m.Stride
+		for x, d := range m.Dist[i : i+m.Rect.Dx()] {
+			v := math.Floor(float64(d)*scale + 0.5)
+			if v > 0xffff {
+				v = 0xffff
+			}
+			dst.SetGray16(m.Rect.Min.X+x, y, color.Gray16{Y: uint16(v)})
+		}
+	}
+	return dst
+}
+
+// DistanceTransform returns the distance map of b, holding the distance from
+// each pixel to the nearest feature pixel, i.e pixel of Bit feature, measured
+// with metric. If nearest is true, the map also holds the coordinates of the
+// nearest feature pixels, which, for chamfer metrics, are the feature pixels
+// the approximated distances have been propagated from.
+func DistanceTransform(b *Image, feature Bit, metric DistanceMetric, nearest bool) *DistanceMap {
+	w, h := b.Rect.Dx(), b.Rect.Dy()
+	m := &DistanceMap{
+		Dist:   make([]float32, w*h),
+		Stride: w,
+		Rect:   b.Rect,
+	}
+
+	features := 0
+	for y := 0; y < h; y++ {
+		i := b.PixOffset(b.Rect.Min.X, b.Rect.Min.Y+y)
+		for _, v := range b.Pix[i : i+w] {
+			if v == feature.V {
+				features++
+			}
+		}
+	}
+	if features == 0 {
+		inf := float32(math.Inf(1))
+		for i := range m.Dist {
+			m.Dist[i] = inf
+		}
+		return m
+	}
+
+	if nearest {
+		m.Nearest = make([]image.Point, w*h)
+	}
+	switch metric {
+	case Chamfer34:
+		chamfer(b, feature, m, []chamferWeight{
+			{-1, 0, 3}, {-1, -1, 4}, {0, -1, 3}, {1, -1, 4},
+		})
+	case Chamfer5711:
+		chamfer(b, feature, m, []chamferWeight{
+			{-1, 0, 5}, {-1, -1, 7}, {0, -1, 5}, {1, -1, 7},
+			{-2, -1, 11}, {-1, -2, 11}, {1, -2, 11}, {2, -1, 11},
+		})
+	default:
+		euclidean(b, feature, m)
+	}
+	return m
+}
+
+// euclidean computes the exact Euclidean distance map of b, with a
+// one-dimensional transform of the columns, then of the rows.
+func euclidean(b *Image, feature Bit, m *DistanceMap) {
+	const inf = 1e20
+	w, h := b.Rect.Dx(), b.Rect.Dy()
+	n := w
+	if h > n {
+		n = h
+	}
+	f, d := make([]float64, n), make([]float64, n)
+	arg, v, z := make([]int, n), make([]int, n), make([]float64, n+1)
+
+	// squared distance to the nearest feature pixel in the same column, and
+	// its row
+	sq := make([]float64, w*h)
+	var rows []int32
+	if m.Nearest != nil {
+		rows = make([]int32, w*h)
+	}
+	for x := 0; x < w; x++ {
+		i := b.PixOffset(b.Rect.Min.X+x, b.Rect.Min.Y)
+		for y := 0; y < h; y++ {
+			f[y] = inf
+			if b.Pix[i] == feature.V {
+				f[y] = 0
+			}
+			i += b.Stride
+		}
+		dt1D(f[:h], d, arg, v, z)
+		for y := 0; y < h; y++ {
+			sq[y*w+x] = d[y]
+			if rows != nil {
+				rows[y*w+x] = int32(arg[y])
+			}
+		}
+	}
+
+	for y := 0; y < h; y++ {
+		row := sq[y*w : (y+1)*w]
+		dt1D(row, d, arg, v, z)
+		for x := 0; x < w; x++ {
+			m.Dist[y*w+x] = float32(math.Sqrt(d[x]))
+			if rows != nil {
+				nx := arg[x]
+				m.Nearest[y*w+x] = image.Pt(b.Rect.Min.X+nx, b.Rect.Min.Y+int(rows[y*w+nx]))
+			}
+		}
+	}
+}
+
+// dt1D computes the one-dimensional squared distance transform of the
+// sampled function f, as the lower envelope of the parabolas rooted at each
+// sample. d receives the transform and arg the index of the sample realizing
+// the minimum, v and z are the parabolas indices and envelope boundaries.
+func dt1D(f, d []float64, arg, v []int, z []float64) {
+	k := 0
+	v[0] = 0
+	z[0], z[1] = math.Inf(-1), math.Inf(1)
+	for q := 1; q < len(f); q++ {
+		fq := f[q] + float64(q*q)
+		s := (fq - (f[v[k]] + float64(v[k]*v[k]))) / float64(2*(q-v[k]))
+		for s <= z[k] {
+			k--
+			s = (fq - (f[v[k]] + float64(v[k]*v[k]))) / float64(2*(q-v[k]))
+		}
+		k++
+		v[k] = q
+		z[k], z[k+1] = s, math.Inf(1)
+	}
+
+	k = 0
+	for q := range f {
+		for z[k+1] < float64(q) {
+			k++
+		}
+		dq := q - v[k]
+		d[q] = float64(dq*dq) + f[v[k]]
+		arg[q] = v[k]
+	}
+}
+
+// A chamferWeight is the weight of the neighbour at (dx, dy) in a chamfer
+// mask.
+type chamferWeight struct {
+	dx, dy int
+	w      int32
+}
+
+// chamfer computes the distance map of b by propagating the distances in a
+// forward then backward raster scan, with the given half mask of the
+// neighbours preceding a pixel in raster order. The first weight is the edge
+// neighbour weight, distances are divided by it.
+func chamfer(b *Image, feature Bit, m *DistanceMap, mask []chamferWeight) {
+	const far = math.MaxInt32 / 2
+	w, h := b.Rect.Dx(), b.Rect.Dy()
+	d := make([]int32, w*h)
+	for y := 0; y < h; y++ {
+		i := b.PixOffset(b.Rect.Min.X, b.Rect.Min.Y+y)
+		for x, v := range b.Pix[i : i+w] {
+			d[y*w+x] = far
+			if v == feature.V {
+				d[y*w+x] = 0
+				if m.Nearest != nil {
+					m.Nearest[y*w+x] = image.Pt(b.Rect.Min.X+x, b.Rect.Min.Y+y)
+				}
+			}
+		}
+	}
+
+	relax := func(x, y, dx, dy int, wt int32) {
+		nx, ny := x+dx, y+dy
+		if nx < 0 || nx >= w || ny < 0 || ny >= h {
+			return
+		}
+		i, j := y*w+x, ny*w+nx
+		if dist := d[j] + wt; dist < d[i] {
+			d[i] = dist
+			if m.Nearest != nil {
+				m.Nearest[i] = m.Nearest[j]
+			}
+		}
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for _, mw := range mask {
+				relax(x, y, mw.dx, mw.dy, mw.w)
+			}
+		}
+	}
+	for y := h - 1; y >= 0; y-- {
+		for x := w - 1; x >= 0; x-- {
+			for _, mw := range mask {
+				relax(x, y, -mw.dx, -mw.dy, mw.w)
+			}
+		}
+	}
+
+	unit := float32(mask[0].w)
+	for i, v := range d {
+		m.Dist[i] = float32(v) / unit
+	}
+}
@@ -0,0 +1,184 @@
+package binimg
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+// randomImage returns an image of bounds r whose pixels are On with
+// probability p.
+func randomImage(r image.Rectangle, p float64, seed int64) *Image {
+	rnd := rand.New(rand.NewSource(seed))
+	b := New(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if rnd.Float64() < p {
+				b.SetBit(x, y, On)
+			}
+		}
+	}
+	return b
+}
+
+// naiveDistance returns the Euclidean distance from (x, y) to the nearest
+// pixel of b of Bit feature.
+func naiveDistance(b *Image, x, y int, feature Bit) float64 {
+	best := math.Inf(1)
+	for fy := b.Rect.Min.Y; fy < b.Rect.Max.Y; fy++ {
+		for fx := b.Rect.Min.X; fx < b.Rect.Max.X; fx++ {
+			if b.BitAt(fx, fy) == feature {
+				best = math.Min(best, math.Hypot(float64(fx-x), float64(fy-y)))
+			}
+		}
+	}
+	return best
+}
+
+func TestDistanceTransformEuclidean(t *testing.T) {
+	b := randomImage(image.Rect(-7, 3, 33, 28), 0.02, 1)
+	for _, feature := range []Bit{On, Off} {
+		m := DistanceTransform(b, feature, Euclidean, true)
+		for y := b.Rect.Min.Y; y < b.Rect.Max.Y; y++ {
+			for x := b.Rect.Min.X; x < b.Rect.Max.X; x++ {
+				want := naiveDistance(b, x, y, feature)
+				got := m.DistanceAt(x, y)
+				if math.Abs(float64(got)-want) > 1e-5 {
+					t.Fatalf("feature %v: distance at (%d,%d): want %v, got %v", feature, x, y, want, got)
+				}
+				// the nearest feature pixel may not be unique, but has to be
+				// at the same distance
+				p, ok := m.NearestAt(x, y)
+				if !ok || b.BitAt(p.X, p.Y) != feature {
+					t.Fatalf("feature %v: nearest at (%d,%d): got %v, not a feature pixel", feature, x, y, p)
+				}
+				if d := math.Hypot(float64(p.X-x), float64(p.Y-y)); math.Abs(d-want) > 1e-5 {
+					t.Fatalf("feature %v: nearest at (%d,%d): got %v at distance %v, want %v", feature, x, y, p, d, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDistanceTransformChamfer(t *testing.T) {
+	b := New(image.Rect(0, 0, 7, 7))
+	b.SetBit(3, 3, On)
+
+	var tests = []struct {
+		metric DistanceMetric
+		x, y   int
+		want   float32
+	}{
+		{Chamfer34, 4, 3, 1},
+		{Chamfer34, 4, 4, 4. / 3},
+		{Chamfer34, 5, 4, 7. / 3},
+		{Chamfer34, 6, 6, 4},
+		{Chamfer5711, 4, 3, 1},
+		{Chamfer5711, 2, 2, 7. / 5},
+		{Chamfer5711, 1, 2, 11. / 5},
+		{Chamfer5711, 6, 5, 18. / 5},
+	}
+	for _, tt := range tests {
+		m := DistanceTransform(b, On, tt.metric, true)
+		if got := m.DistanceAt(tt.x, tt.y); math.Abs(float64(got-tt.want)) > 1e-6 {
+			t.Errorf("metric %d: distance at (%d,%d): want %v, got %v", tt.metric, tt.x, tt.y, tt.want, got)
+		}
+		if p, _ := m.NearestAt(tt.x, tt.y); p != image.Pt(3, 3) {
+			t.Errorf("metric %d: nearest at (%d,%d): want (3,3), got %v", tt.metric, tt.x, tt.y, p)
+		}
+	}
+}
+
+func TestDistanceTransformChamferError(t *testing.T) {
+	b := randomImage(image.Rect(0, 0, 80, 60), 0.005, 2)
+	exact := DistanceTransform(b, On, Euclidean, false)
+
+	// maximum relative errors of chamfer distances
+	var tests = []struct {
+		metric DistanceMetric
+		maxErr float64
+	}{
+		{Chamfer34, 0.09},
+		{Chamfer5711, 0.03},
+	}
+	for _, tt := range tests {
+		m := DistanceTransform(b, On, tt.metric, false)
+		for i, d := range m.Dist {
+			e := float64(exact.Dist[i])
+			if e == 0 {
+				if d != 0 {
+					t.Fatalf("metric %d: want feature pixel at distance 0, got %v", tt.metric, d)
+				}
+				continue
+			}
+			if rel := math.Abs(float64(d)-e) / e; rel > tt.maxErr {
+				t.Fatalf("metric %d: relative error %v at index %d (want %v, got %v)", tt.metric, rel, i, e, d)
+			}
+		}
+	}
+}
+
+func TestDistanceTransformNoFeature(t *testing.T) {
+	b := New(image.Rect(0, 0, 5, 4))
+	for _, metric := range []DistanceMetric{Euclidean, Chamfer34, Chamfer5711} {
+		m := DistanceTransform(b, On, metric, true)
+		if m.Nearest != nil {
+			t.Errorf("metric %d: want nil Nearest without feature pixels", metric)
+		}
+		for i, d := range m.Dist {
+			if !math.IsInf(float64(d), 1) {
+				t.Fatalf("metric %d: want infinite distance at index %d, got %v", metric, i, d)
+			}
+		}
+		if _, ok := m.NearestAt(0, 0); ok {
+			t.Errorf("metric %d: want NearestAt not ok without feature pixels", metric)
+		}
+	}
+}
+
+func TestDistanceMapGray16(t *testing.T) {
+	b := New(image.Rect(2, 1, 12, 2))
+	b.SetBit(2, 1, On)
+	m := DistanceTransform(b, On, Euclidean, false)
+
+	g := m.Gray16(10000)
+	if g.Rect != b.Rect {
+		t.Errorf("want bounds %v, got %v", b.Rect, g.Rect)
+	}
+	for x := 2; x < 12; x++ {
+		want := uint16(10000 * (x - 2))
+		if x-2 > 6 {
+			want = 0xffff
+		}
+		if got := g.Gray16At(x, 1).Y; got != want {
+			t.Errorf("pixel %d: want %d, got %d", x, want, got)
+		}
+	}
+	if g := New(image.Rect(0, 0, 2, 2)); DistanceTransform(g, On, Chamfer34, false).Gray16(1).Gray16At(1, 1).Y != 0xffff {
+		t.Errorf("want infinite distances clamped to 0xffff")
+	}
+}
+
+func BenchmarkDistanceTransform(b *testing.B) {
+	src, err := test.LoadPNG("../testdata/big.png")
+	test.CheckB(b, err)
+	bin := NewFromImage(src)
+
+	for _, metric := range []struct {
+		name   string
+		metric DistanceMetric
+	}{
+		{"Euclidean", Euclidean},
+		{"Chamfer34", Chamfer34},
+		{"Chamfer5711", Chamfer5711},
+	} {
+		b.Run(metric.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				DistanceTransform(bin, On, metric.metric, true)
+			}
+		})
+	}
+}
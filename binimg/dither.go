@@ -0,0 +1,142 @@
+package binimg
+
+import "image"
+
+// A DiffusionKernel describes how an error diffusion dithering distributes the
+// quantization error of a pixel to its not yet processed neighbours.
+type DiffusionKernel struct {
+	// Weights are the neighbours receiving a part of the error, relatively to
+	// the current pixel, when scanning from left to right.
+	Weights []DiffusionWeight
+	// Divisor is the value by which each weight is divided.
+	Divisor int
+}
+
+// A DiffusionWeight is the weight of the error diffused to the neighbour at
+// offset (DX, DY) of the current pixel.
+type DiffusionWeight struct {
+	DX, DY int
+	Weight int
+}
+
+// valid reports whether k can be used to diffuse the quantization error: its
+// divisor is positive and it has weights, all targeting neighbours that are
+// processed after the current pixel.
+func (k *DiffusionKernel) valid() bool {
+	if k == nil || k.Divisor <= 0 || len(k.Weights) == 0 {
+		return false
+	}
+	for _, kw := range k.Weights {
+		if kw.DY < 0 || (kw.DY == 0 && kw.DX <= 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// Predefined error diffusion kernels.
+var (
+	FloydSteinberg = &DiffusionKernel{
+		Weights: []DiffusionWeight{
+			{1, 0, 7},
+			{-1, 1, 3}, {0, 1, 5}, {1, 1, 1},
+		},
+		Divisor: 16,
+	}
+
+	// Atkinson only diffuses 3/4 of the error, giving more contrast.
+	Atkinson = &DiffusionKernel{
+		Weights: []DiffusionWeight{
+			{1, 0, 1}, {2, 0, 1},
+			{-1, 1, 1}, {0, 1, 1}, {1, 1, 1},
+			{0, 2, 1},
+		},
+		Divisor: 8,
+	}
+
+	JarvisJudiceNinke = &DiffusionKernel{
+		Weights: []DiffusionWeight{
+			{1, 0, 7}, {2, 0, 5},
+			{-2, 1, 3}, {-1, 1, 5}, {0, 1, 7}, {1, 1, 5}, {2, 1, 3},
+			{-2, 2, 1}, {-1, 2, 3}, {0, 2, 5}, {1, 2, 3}, {2, 2, 1},
+		},
+		Divisor: 48,
+	}
+
+	Stucki = &DiffusionKernel{
+		Weights: []DiffusionWeight{
+			{1, 0, 8}, {2, 0, 4},
+			{-2, 1, 2}, {-1, 1, 4}, {0, 1, 8}, {1, 1, 4}, {2, 1, 2},
+			{-2, 2, 1}, {-1, 2, 2}, {0, 2, 4}, {1, 2, 2}, {2, 2, 1},
+		},
+		Divisor: 42,
+	}
+
+	Sierra = &DiffusionKernel{
+		Weights: []DiffusionWeight{
+			{1, 0, 5}, {2, 0, 3},
+			{-2, 1, 2}, {-1, 1, 4}, {0, 1, 5}, {1, 1, 4}, {2, 1, 2},
+			{-1, 2, 2}, {0, 2, 3}, {1, 2, 2},
+		},
+		Divisor: 32,
+	}
+)
+
+// NewDithered returns a new binary image that is the conversion of src image
+// using error diffusion dithering with kernel k. The Rec.601 luminance of each
+// pixel is thresholded at the middle of the luminance range, and the
+// quantization error is diffused to the neighbours of the pixel, as described
+// by k.
+//
+// If serpentine is true, lines are alternatively scanned from left to right
+// and from right to left, the kernel being mirrored on the latter, which
+// reduces directional artifacts.
+//
+// NewDithered panics if k is not valid, that is if its divisor is not
+// positive, if it has no weights, or if a weight doesn't target a neighbour
+// processed after the current pixel.
+func NewDithered(src image.Image, k *DiffusionKernel, serpentine bool) *Image {
+	if !k.valid() {
+		panic("binimg: invalid diffusion kernel")
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dst := New(b)
+
+	// luminance plus accumulated error, per pixel, scaled by the kernel
+	// divisor to keep the diffusion in integer arithmetic.
+	plane := lumaPlane(src)
+	lum := make([]int, len(plane))
+	for i, v := range plane {
+		lum[i] = int(v) * k.Divisor
+	}
+
+	for y := 0; y < h; y++ {
+		x, dx := 0, 1
+		if serpentine && y%2 == 1 {
+			x, dx = w-1, -1
+		}
+		for ; x >= 0 && x < w; x += dx {
+			i := y*w + x
+			old := lum[i]
+			var qerr int
+			if old >= 128*k.Divisor {
+				dst.Pix[dst.PixOffset(b.Min.X+x, b.Min.Y+y)] = On.V
+				qerr = old - 255*k.Divisor
+			} else {
+				qerr = old
+			}
+			if qerr == 0 {
+				continue
+			}
+			for _, kw := range k.Weights {
+				nx, ny := x+kw.DX*dx, y+kw.DY
+				if nx < 0 || nx >= w || ny >= h {
+					continue
+				}
+				lum[ny*w+nx] += qerr * kw.Weight / k.Divisor
+			}
+		}
+	}
+	return dst
+}
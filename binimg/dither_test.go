@@ -0,0 +1,133 @@
+package binimg
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// onDensity returns the proportion of On pixels of b.
+func onDensity(b *Image) float64 {
+	var n int
+	for _, v := range b.Pix {
+		if v == On.V {
+			n++
+		}
+	}
+	return float64(n) / float64(len(b.Pix))
+}
+
+func TestDitheredDensity(t *testing.T) {
+	kernels := map[string]*DiffusionKernel{
+		"floyd-steinberg":     FloydSteinberg,
+		"atkinson":            Atkinson,
+		"jarvis-judice-ninke": JarvisJudiceNinke,
+		"stucki":              Stucki,
+		"sierra":              Sierra,
+	}
+
+	for name, k := range kernels {
+		for _, serpentine := range []bool{false, true} {
+			for _, level := range []uint8{0, 32, 64, 128, 192, 255} {
+				src := image.NewRGBA(image.Rect(-10, 5, 90, 105))
+				for i := 0; i < len(src.Pix); i += 4 {
+					src.Pix[i], src.Pix[i+1], src.Pix[i+2], src.Pix[i+3] = level, level, level, 255
+				}
+
+				bin := NewDithered(src, k, serpentine)
+				if bin.Rect != src.Rect {
+					t.Fatalf("%s: want bounds %v, got %v", name, src.Rect, bin.Rect)
+				}
+				want, got := float64(level)/255, onDensity(bin)
+				// Atkinson doesn't diffuse the whole error, it loses
+				// details in the shadows and the highlights
+				tolerance := 0.02
+				if k == Atkinson {
+					tolerance = 0.15
+				}
+				if got < want-tolerance || got > want+tolerance {
+					t.Errorf("%s (serpentine=%v): want density of %v for level %d, got %v", name, serpentine, want, level, got)
+				}
+			}
+		}
+	}
+}
+
+func TestDitheredSerpentine(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			src.SetGray(x, y, color.Gray{uint8(4 * x)})
+		}
+	}
+
+	raster := NewDithered(src, FloydSteinberg, false)
+	serpentine := NewDithered(src, FloydSteinberg, true)
+
+	// the first line is scanned from left to right in both cases
+	for x := 0; x < 64; x++ {
+		if raster.BitAt(x, 0) != serpentine.BitAt(x, 0) {
+			t.Fatalf("want same first line, got different bits at x=%d", x)
+		}
+	}
+
+	same := true
+	for i := range raster.Pix {
+		if raster.Pix[i] != serpentine.Pix[i] {
+			same = false
+			break
+		}
+	}
+	if same {
+		t.Errorf("want serpentine scanning to produce a different image")
+	}
+}
+
+func TestDitheredFloydSteinberg(t *testing.T) {
+	// the error of the first pixel (100) is diffused to its right neighbour
+	// (7/16), which becomes On, and to the line below (5/16 and 1/16).
+	src := image.NewGray(image.Rect(0, 0, 3, 2))
+	copy(src.Pix, []uint8{
+		100, 100, 0,
+		200, 200, 0,
+	})
+	bin := NewDithered(src, FloydSteinberg, false)
+
+	want := []Bit{
+		Off, On, Off,
+		On, On, Off,
+	}
+	for i, bit := range want {
+		if got := bin.BitAt(i%3, i/3); got != bit {
+			t.Errorf("want %v at (%d,%d), got %v", bit, i%3, i/3, got)
+		}
+	}
+}
+
+func TestDitheredInvalidKernel(t *testing.T) {
+	weights := FloydSteinberg.Weights
+	var tests = []struct {
+		name string
+		k    *DiffusionKernel
+	}{
+		{"nil", nil},
+		{"zero divisor", &DiffusionKernel{Weights: weights}},
+		{"negative divisor", &DiffusionKernel{Weights: weights, Divisor: -16}},
+		{"no weights", &DiffusionKernel{Divisor: 16}},
+		{"previous line", &DiffusionKernel{Weights: []DiffusionWeight{{0, -1, 1}}, Divisor: 1}},
+		{"previous pixel", &DiffusionKernel{Weights: []DiffusionWeight{{-1, 0, 1}}, Divisor: 1}},
+		{"current pixel", &DiffusionKernel{Weights: []DiffusionWeight{{0, 0, 1}}, Divisor: 1}},
+	}
+
+	src := image.NewGray(image.Rect(0, 0, 4, 4))
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: want NewDithered to panic", tt.name)
+				}
+			}()
+			NewDithered(src, tt.k, false)
+		}()
+	}
+}
@@ -0,0 +1,113 @@
+package binimg
+
+import "image"
+
+// FloodFill sets to c the pixels of the region of b connected to the seed
+// pixel, with connectivity conn, and made of pixels of the Bit of the seed.
+// It returns the bounding box of the filled region, which is empty if the
+// seed is out of the bounds of b or is already c.
+//
+// The region is filled span by span, with an explicit stack, so that large
+// regions can be filled.
+func FloodFill(b *Image, seed image.Point, c Bit, conn Connectivity) image.Rectangle {
+	if !seed.In(b.Rect) {
+		return image.Rectangle{}
+	}
+	target := b.Pix[b.PixOffset(seed.X, seed.Y)]
+	if target == c.V {
+		return image.Rectangle{}
+	}
+
+	// diagonal neighbours of a span extend it by one pixel on each side
+	ext := 0
+	if conn == Connectivity8 {
+		ext = 1
+	}
+	bounds := image.Rectangle{seed, seed.Add(image.Pt(1, 1))}
+	stack := []image.Point{seed}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		i := b.PixOffset(p.X, p.Y)
+		if b.Pix[i] != target {
+			// filled since pushed
+			continue
+		}
+
+		// extend the span on both sides, then fill it
+		x0, x1 := p.X, p.X+1
+		for x0 > b.Rect.Min.X && b.Pix[i-(p.X-x0)-1] == target {
+			x0--
+		}
+		for x1 < b.Rect.Max.X && b.Pix[i+(x1-p.X)] == target {
+			x1++
+		}
+		row := b.Pix[i-(p.X-x0) : i+(x1-p.X)]
+		for k := range row {
+			row[k] = c.V
+		}
+		bounds = bounds.Union(image.Rect(x0, p.Y, x1, p.Y+1))
+
+		// push a seed for each span of target pixels touching the filled
+		// span, on the lines above and below
+		sx0, sx1 := x0-ext, x1+ext
+		if sx0 < b.Rect.Min.X {
+			sx0 = b.Rect.Min.X
+		}
+		if sx1 > b.Rect.Max.X {
+			sx1 = b.Rect.Max.X
+		}
+		for _, y := range [2]int{p.Y - 1, p.Y + 1} {
+			if y < b.Rect.Min.Y || y >= b.Rect.Max.Y {
+				continue
+			}
+			j := b.PixOffset(sx0, y)
+			inSpan := false
+			for x := sx0; x < sx1; x++ {
+				if b.Pix[j] == target {
+					if !inSpan {
+						stack = append(stack, image.Pt(x, y))
+						inSpan = true
+					}
+				} else {
+					inSpan = false
+				}
+				j++
+			}
+		}
+	}
+	return bounds
+}
+
+// FillHoles returns a new image, with the same bounds and palette as b, in
+// which the holes of b have been filled with On pixels. A hole is a region of
+// Off pixels, connected with connectivity conn, that doesn't touch the border
+// of the image.
+func FillHoles(b *Image, conn Connectivity) *Image {
+	// fill the Off regions touching the border, the remaining Off pixels are
+	// the holes.
+	r := b.Rect
+	bg := NewWithPalette(r, b.Palette)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i, j := bg.PixOffset(r.Min.X, y), b.PixOffset(r.Min.X, y)
+		copy(bg.Pix[i:i+r.Dx()], b.Pix[j:j+r.Dx()])
+	}
+	for x := r.Min.X; x < r.Max.X; x++ {
+		FloodFill(bg, image.Pt(x, r.Min.Y), On, conn)
+		FloodFill(bg, image.Pt(x, r.Max.Y-1), On, conn)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		FloodFill(bg, image.Pt(r.Min.X, y), On, conn)
+		FloodFill(bg, image.Pt(r.Max.X-1, y), On, conn)
+	}
+
+	// bg becomes the result: pixels On in b, or Off in bg
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i, j := bg.PixOffset(r.Min.X, y), b.PixOffset(r.Min.X, y)
+		row, src := bg.Pix[i:i+r.Dx()], b.Pix[j:j+r.Dx()]
+		for k := range row {
+			row[k] = src[k] | ^row[k]
+		}
+	}
+	return bg
+}
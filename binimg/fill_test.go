@@ -0,0 +1,241 @@
+package binimg
+
+import (
+	"image"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestFloodFill(t *testing.T) {
+	src := newFromString(image.Pt(-1, 2), []string{
+		"11111111",
+		"10001001",
+		"10110101",
+		"10001001",
+		"11110111",
+		"00011000",
+	})
+
+	var tests = []struct {
+		seed   image.Point
+		c      Bit
+		conn   Connectivity
+		bounds image.Rectangle
+		want   []string
+	}{
+		{
+			image.Pt(0, 3), On, Connectivity4,
+			image.Rect(0, 3, 3, 6),
+			[]string{
+				"11111111",
+				"11111001",
+				"11110101",
+				"11111001",
+				"11110111",
+				"00011000",
+			},
+		},
+		{
+			// the diagonal gap connects to the right region
+			image.Pt(0, 3), On, Connectivity8,
+			image.Rect(0, 3, 7, 8),
+			[]string{
+				"11111111",
+				"11111111",
+				"11111111",
+				"11111111",
+				"11111111",
+				"00011111",
+			},
+		},
+		{
+			image.Pt(-1, 2), Off, Connectivity4,
+			image.Rect(-1, 2, 7, 8),
+			[]string{
+				"00000000",
+				"00000000",
+				"00110100",
+				"00001000",
+				"00000000",
+				"00000000",
+			},
+		},
+		{
+			// seed already of the fill color
+			image.Pt(-1, 2), On, Connectivity4,
+			image.Rectangle{},
+			[]string{
+				"11111111",
+				"10001001",
+				"10110101",
+				"10001001",
+				"11110111",
+				"00011000",
+			},
+		},
+		{
+			// seed out of bounds
+			image.Pt(-2, 2), On, Connectivity4,
+			image.Rectangle{},
+			[]string{
+				"11111111",
+				"10001001",
+				"10110101",
+				"10001001",
+				"11110111",
+				"00011000",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		b := newFromString(src.Rect.Min, []string{
+			"11111111",
+			"10001001",
+			"10110101",
+			"10001001",
+			"11110111",
+			"00011000",
+		})
+		bounds := FloodFill(b, tt.seed, tt.c, tt.conn)
+		if bounds != tt.bounds {
+			t.Errorf("FloodFill(%v, %v, %d): want bounds %v, got %v", tt.seed, tt.c, tt.conn, tt.bounds, bounds)
+		}
+		if err := test.Diff(newFromString(src.Rect.Min, tt.want), b); err != nil {
+			t.Errorf("FloodFill(%v, %v, %d): %v", tt.seed, tt.c, tt.conn, err)
+		}
+	}
+}
+
+// checkFloodFill checks that filling from seed fills exactly the connected
+// component of seed, as found by Label.
+func checkFloodFill(t *testing.T, b *Image, seed image.Point, conn Connectivity) {
+	bit := b.BitAt(seed.X, seed.Y)
+	comps := b
+	if bit == Off {
+		comps = Not(b)
+	}
+	labels := Label(comps, conn)
+	l := labels.LabelAt(seed.X, seed.Y)
+
+	filled := Not(Not(b)) // copy
+	bounds := FloodFill(filled, seed, bit.Other(), conn)
+	if want := labels.Components[l-1].Bounds; bounds != want {
+		t.Errorf("seed %v, conn %d: want bounds %v, got %v", seed, conn, want, bounds)
+	}
+	for y := b.Rect.Min.Y; y < b.Rect.Max.Y; y++ {
+		for x := b.Rect.Min.X; x < b.Rect.Max.X; x++ {
+			want := b.BitAt(x, y)
+			if labels.LabelAt(x, y) == l {
+				want = want.Other()
+			}
+			if got := filled.BitAt(x, y); got != want {
+				t.Fatalf("seed %v, conn %d: pixel (%d,%d): want %v, got %v", seed, conn, x, y, want, got)
+			}
+		}
+	}
+}
+
+func TestFloodFillImages(t *testing.T) {
+	for _, tt := range []struct {
+		filename string
+		seeds    []image.Point
+	}{
+		{"../testdata/bwgopher.png", []image.Point{{0, 0}, {240, 240}, {300, 100}, {190, 200}}},
+		{"../testdata/big.png", []image.Point{{0, 0}, {1800, 1000}, {3000, 2000}}},
+	} {
+		src, err := test.LoadPNG(tt.filename)
+		test.Check(t, err)
+		b := NewFromImage(src)
+		for _, seed := range tt.seeds {
+			for _, conn := range []Connectivity{Connectivity4, Connectivity8} {
+				checkFloodFill(t, b, seed, conn)
+			}
+		}
+	}
+}
+
+func TestFillHoles(t *testing.T) {
+	src := newFromString(image.Pt(2, 2), []string{
+		"0000000000",
+		"0111101110",
+		"0100101010",
+		"0111101100",
+		"0000000000",
+		"1100000000",
+		"0100000000",
+	})
+
+	var tests = []struct {
+		conn Connectivity
+		want []string
+	}{
+		{
+			// the right shape is open through a corner: its Off region is a
+			// hole with 4-connectivity only
+			Connectivity4,
+			[]string{
+				"0000000000",
+				"0111101110",
+				"0111101110",
+				"0111101100",
+				"0000000000",
+				"1100000000",
+				"0100000000",
+			},
+		},
+		{
+			Connectivity8,
+			[]string{
+				"0000000000",
+				"0111101110",
+				"0111101010",
+				"0111101100",
+				"0000000000",
+				"1100000000",
+				"0100000000",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got := FillHoles(src, tt.conn)
+		if err := test.Diff(newFromString(src.Rect.Min, tt.want), got); err != nil {
+			t.Errorf("FillHoles(%d): %v", tt.conn, err)
+		}
+	}
+}
+
+func TestFillHolesImages(t *testing.T) {
+	for _, filename := range []string{"../testdata/bwgopher.png", "../testdata/big.png"} {
+		src, err := test.LoadPNG(filename)
+		test.Check(t, err)
+		b := NewFromImage(src)
+		sub := b.SubImage(image.Rect(5, 7, 300, 401)).(*Image)
+
+		for _, img := range []*Image{b, sub} {
+			for _, conn := range []Connectivity{Connectivity4, Connectivity8} {
+				// holes are the Off components not touching the border
+				want := Not(Not(img))
+				labels := Label(Not(img), conn)
+				r := img.Rect
+				for _, c := range labels.Components {
+					if c.Bounds.Min.X == r.Min.X || c.Bounds.Min.Y == r.Min.Y || c.Bounds.Max.X == r.Max.X || c.Bounds.Max.Y == r.Max.Y {
+						continue
+					}
+					for y := c.Bounds.Min.Y; y < c.Bounds.Max.Y; y++ {
+						for x := c.Bounds.Min.X; x < c.Bounds.Max.X; x++ {
+							if labels.LabelAt(x, y) == c.Label {
+								want.SetBit(x, y, On)
+							}
+						}
+					}
+				}
+				if err := test.Diff(want, FillHoles(img, conn)); err != nil {
+					t.Errorf("%s %v, conn %d: %v", filename, r, conn, err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package binimg
+
+import (
+	"errors"
+	"fmt"
+	"image"
+)
+
+// HitOrMiss returns the hit-or-miss transform of src by the foreground and
+// background structuring elements fg and bg: a pixel is On if, with both
+// elements placed at their origin on that pixel, all the pixels of src
+// covered by fg are On and all the pixels covered by bg are Off. Pixels out of
+// the bounds of src are Off. A nil element covers no pixels.
+//
+// The returned image, a mask of the pixels matching the pattern, has the same
+// bounds and palette as src.
+func HitOrMiss(src *Image, fg, bg *Element) *Image {
+	dst := NewWithPalette(src.Rect, src.Palette)
+	dst.SetRect(dst.Rect, On)
+	b := src.Rect
+	if fg != nil {
+		for _, d := range fg.offsets() {
+			// the pixels whose shifted position is in src, others can't match
+			r := b.Intersect(b.Sub(d))
+			if r.Empty() {
+				dst.SetRect(b, Off)
+				return dst
+			}
+			dst.SetRect(image.Rect(b.Min.X, b.Min.Y, b.Max.X, r.Min.Y), Off)
+			dst.SetRect(image.Rect(b.Min.X, r.Max.Y, b.Max.X, b.Max.Y), Off)
+			dst.SetRect(image.Rect(b.Min.X, r.Min.Y, r.Min.X, r.Max.Y), Off)
+			dst.SetRect(image.Rect(r.Max.X, r.Min.Y, b.Max.X, r.Max.Y), Off)
+			hitOrMissRows(dst, src, r, d, false)
+		}
+	}
+	if bg != nil {
+		for _, d := range bg.offsets() {
+			r := b.Intersect(b.Sub(d))
+			if !r.Empty() {
+				hitOrMissRows(dst, src, r, d, true)
+			}
+		}
+	}
+	return dst
+}
+
+// hitOrMissRows clears the pixels of dst in r whose pixel of src, shifted by
+// d, is Off, or On if miss is true.
+func hitOrMissRows(dst, src *Image, r image.Rectangle, d image.Point, miss bool) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		si := src.PixOffset(r.Min.X+d.X, y+d.Y)
+		di := dst.PixOffset(r.Min.X, y)
+		srow, drow := src.Pix[si:si+r.Dx()], dst.Pix[di:di+r.Dx()]
+		if miss {
+			for i, v := range srow {
+				drow[i] &^= v
+			}
+		} else {
+			for i, v := range srow {
+				drow[i] &= v
+			}
+		}
+	}
+}
+
+// ParseHitOrMiss returns the foreground and background structuring elements
+// of the pattern described by ss, one string per row, in which '1' cells are
+// foreground pixels, '0' cells are background pixels, and 'x' (or 'X') cells
+// are "don't care" pixels. origin is the cell of the pattern aligned with the
+// processed pixel, relatively to the top-left cell.
+//
+// For example, the isolated On pixels are matched by:
+//
+//	fg, bg, err := ParseHitOrMiss([]string{
+//		"000",
+//		"010",
+//		"000",
+//	}, image.Pt(1, 1))
+func ParseHitOrMiss(ss []string, origin image.Point) (fg, bg *Element, err error) {
+	if len(ss) == 0 || len(ss[0]) == 0 {
+		return nil, nil, errors.New("binimg: empty hit-or-miss pattern")
+	}
+	r := image.Rect(0, 0, len(ss[0]), len(ss))
+	fg = NewElement(New(r), origin)
+	bg = NewElement(New(r), origin)
+	for y, s := range ss {
+		if len(s) != r.Dx() {
+			return nil, nil, fmt.Errorf("binimg: hit-or-miss pattern row %d has length %d, want %d", y, len(s), r.Dx())
+		}
+		for x := 0; x < len(s); x++ {
+			switch s[x] {
+			case '1':
+				fg.Kernel.SetBit(x, y, On)
+			case '0':
+				bg.Kernel.SetBit(x, y, On)
+			case 'x', 'X':
+			default:
+				return nil, nil, fmt.Errorf("binimg: invalid hit-or-miss pattern cell %q at (%d,%d)", s[x], x, y)
+			}
+		}
+	}
+	return fg, bg, nil
+}
@@ -0,0 +1,157 @@
+package binimg
+
+import (
+	"image"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+// naiveHitOrMiss is the straightforward implementation of the hit-or-miss
+// transform of src by fg and bg.
+func naiveHitOrMiss(src *Image, fg, bg *Element) *Image {
+	dst := New(src.Rect)
+	for y := src.Rect.Min.Y; y < src.Rect.Max.Y; y++ {
+		for x := src.Rect.Min.X; x < src.Rect.Max.X; x++ {
+			match := true
+			for _, d := range fg.offsets() {
+				// out of bounds pixels are Off
+				if src.BitAt(x+d.X, y+d.Y) != On {
+					match = false
+				}
+			}
+			for _, d := range bg.offsets() {
+				if src.BitAt(x+d.X, y+d.Y) == On {
+					match = false
+				}
+			}
+			if match {
+				dst.SetBit(x, y, On)
+			}
+		}
+	}
+	return dst
+}
+
+func TestHitOrMiss(t *testing.T) {
+	src := newFromString(image.Pt(-2, 3), []string{
+		"10000000",
+		"00011100",
+		"01011100",
+		"00011100",
+		"00000001",
+	})
+
+	var tests = []struct {
+		name    string
+		pattern []string
+		origin  image.Point
+		want    []string
+	}{
+		{
+			"isolated pixels",
+			[]string{
+				"000",
+				"010",
+				"000",
+			},
+			image.Pt(1, 1),
+			[]string{
+				"10000000",
+				"00000000",
+				"01000000",
+				"00000000",
+				"00000001",
+			},
+		},
+		{
+			"top-left corners",
+			[]string{
+				"x0x",
+				"011",
+				"x1x",
+			},
+			image.Pt(1, 1),
+			[]string{
+				"00000000",
+				"00010000",
+				"00000000",
+				"00000000",
+				"00000000",
+			},
+		},
+		{
+			"right ends of horizontal lines, origin on the last On pixel",
+			[]string{
+				"10",
+			},
+			image.Pt(0, 0),
+			[]string{
+				"10000000",
+				"00000100",
+				"01000100",
+				"00000100",
+				"00000001",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		fg, bg, err := ParseHitOrMiss(tt.pattern, tt.origin)
+		test.Check(t, err)
+		got := HitOrMiss(src, fg, bg)
+		if err := test.Diff(newFromString(src.Rect.Min, tt.want), got); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+}
+
+func TestHitOrMissRandom(t *testing.T) {
+	src := randomImage(image.Rect(3, -4, 45, 30), 0.5, 3)
+	var patterns = [][]string{
+		{"1x0", "x1x", "0x1"},
+		{"x0x", "010", "x0x"},
+		{"11", "10"},
+		{"1xxxx0"},
+	}
+	for _, p := range patterns {
+		for _, origin := range []image.Point{{0, 0}, {1, 1}, {-2, 5}} {
+			fg, bg, err := ParseHitOrMiss(p, origin)
+			test.Check(t, err)
+			if err := test.Diff(naiveHitOrMiss(src, fg, bg), HitOrMiss(src, fg, bg)); err != nil {
+				t.Errorf("pattern %v, origin %v: %v", p, origin, err)
+			}
+		}
+	}
+}
+
+func TestHitOrMissNilElements(t *testing.T) {
+	src := newFromString(image.Pt(0, 0), []string{
+		"101",
+		"000",
+	})
+	fg, _, err := ParseHitOrMiss([]string{"1"}, image.Point{})
+	test.Check(t, err)
+	if err := test.Diff(src, HitOrMiss(src, fg, nil)); err != nil {
+		t.Errorf("want nil background element to match the foreground: %v", err)
+	}
+	all := New(src.Rect)
+	all.SetRect(all.Rect, On)
+	if err := test.Diff(all, HitOrMiss(src, nil, nil)); err != nil {
+		t.Errorf("want all pixels to match nil elements: %v", err)
+	}
+}
+
+func TestParseHitOrMissErrors(t *testing.T) {
+	var tests = [][]string{
+		nil,
+		{""},
+		{"01", "0"},
+		{"012"},
+	}
+	for _, ss := range tests {
+		if _, _, err := ParseHitOrMiss(ss, image.Point{}); err == nil {
+			t.Errorf("ParseHitOrMiss(%q): want error, got nil", ss)
+		}
+	}
+}
@@ -7,7 +7,8 @@
 // Though the information represented by each pixel could be stored as a single
 // bit, and thus has a reduced memory footprint, choice has been made to
 // represent Bit pixels as byte values, that can either be 0 (Black or Off) or
-// 255 (White or On), mostly for simplicity reasons.
+// 255 (White or On), mostly for simplicity reasons. When memory footprint
+// matters more than speed, binimg.Packed stores 8 pixels per byte.
 //
 // binimg.Image images are created either by calling functions such as
 // New and NewFromImage.
@@ -52,20 +53,9 @@ func (c Bit) Other() Bit {
 }
 
 // Model is the color model for binary images.
-var Model = color.ModelFunc(model)
-
-func model(c color.Color) color.Color {
-	if _, ok := c.(Bit); ok {
-		return c
-	}
-	r, g, b, _ := c.RGBA()
-
-	y := (299*r + 587*g + 114*b + 500) / 1000
-	if uint8(y>>8) > 97 {
-		return White
-	}
-	return Black
-}
+//
+// Colors are converted to Bit by thresholding their Rec.601 luminance at 97.
+var Model = ThresholdModel(97, Rec601)
 
 // Image is an in-memory image whose At method returns Bit values.
 type Image struct {
@@ -76,10 +66,18 @@ type Image struct {
 	Stride int
 	// Rect is the image's bounds.
 	Rect image.Rectangle
+	// Palette is the image's palette. If nil, On and Off pixels are
+	// respectively rendered as White and Black.
+	Palette *Palette
 }
 
 // ColorModel returns the image.Image's color model.
-func (b *Image) ColorModel() color.Model { return Model }
+func (b *Image) ColorModel() color.Model {
+	if b.Palette != nil {
+		return b.Palette
+	}
+	return Model
+}
 
 // Bounds returns the domain for which At can return non-zero color.
 // The bounds do not necessarily contain the point (0, 0).
@@ -89,7 +87,7 @@ func (b *Image) Bounds() image.Rectangle { return b.Rect }
 // At(Bounds().Min.X, Bounds().Min.Y) returns the upper-left pixel of the grid.
 // At(Bounds().Max.X-1, Bounds().Max.Y-1) returns the lower-right one.
 func (b *Image) At(x, y int) color.Color {
-	return b.BitAt(x, y)
+	return b.Palette.Color(b.BitAt(x, y))
 }
 
 // BitAt returns the Bit color of the pixel at (x, y).
@@ -112,13 +110,13 @@ func (b *Image) PixOffset(x, y int) int {
 
 // Set sets the color of the pixel at (x, y).
 //
-// c is converted to Bit using the image color model.
+// c is converted to Bit using the image palette.
 func (b *Image) Set(x, y int, c color.Color) {
 	if !(image.Point{x, y}.In(b.Rect)) {
 		return
 	}
 	i := b.PixOffset(x, y)
-	b.Pix[i] = Model.Convert(c).(Bit).V
+	b.Pix[i] = b.Palette.Bit(c).V
 }
 
 // SetBit sets the Bit of the pixel at (x, y).
@@ -157,14 +155,27 @@ func (b *Image) SubImage(r image.Rectangle) image.Image {
 	}
 	i := b.PixOffset(r.Min.X, r.Min.Y)
 	return &Image{
-		Pix:    b.Pix[i:],
-		Stride: b.Stride,
-		Rect:   r,
+		Pix:     b.Pix[i:],
+		Stride:  b.Stride,
+		Rect:    r,
+		Palette: b.Palette,
 	}
 }
 
 // Opaque scans the entire image and reports whether it is fully opaque.
 func (b *Image) Opaque() bool {
+	if b.Palette.isOpaque(On) && b.Palette.isOpaque(Off) {
+		return true
+	}
+	for y := b.Rect.Min.Y; y < b.Rect.Max.Y; y++ {
+		i := b.PixOffset(b.Rect.Min.X, y)
+		j := b.PixOffset(b.Rect.Max.X, y)
+		for _, v := range b.Pix[i:j] {
+			if !b.Palette.isOpaque(Bit{v}) {
+				return false
+			}
+		}
+	}
 	return true
 }
 
@@ -172,7 +183,15 @@ func (b *Image) Opaque() bool {
 func New(r image.Rectangle) *Image {
 	w, h := r.Dx(), r.Dy()
 	pix := make([]uint8, 1*w*h)
-	return &Image{pix, 1 * w, r}
+	return &Image{pix, 1 * w, r, nil}
+}
+
+// NewWithPalette returns a new binary image with the given bounds and
+// palette.
+func NewWithPalette(r image.Rectangle, p *Palette) *Image {
+	b := New(r)
+	b.Palette = p
+	return b
 }
 
 // NewFromImage returns a new binary image that is the conversion of src image.
@@ -181,3 +200,20 @@ func NewFromImage(src image.Image) *Image {
 	draw.Draw(dst, dst.Bounds(), src, image.Point{}, draw.Src)
 	return dst
 }
+
+// NewFromImageWithModel returns a new binary image that is the conversion of
+// src image, using the color model m.
+//
+// Colors returned by m that are not Bit values are converted with Model.
+func NewFromImageWithModel(src image.Image, m color.Model) *Image {
+	b := src.Bounds()
+	dst := New(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := dst.PixOffset(b.Min.X, y)
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.Pix[i] = Model.Convert(m.Convert(src.At(x, y))).(Bit).V
+			i++
+		}
+	}
+	return dst
+}
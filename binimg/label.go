@@ -0,0 +1,219 @@
+package binimg
+
+import "image"
+
+// Connectivity defines which pixels are neighbours.
+type Connectivity int
+
+const (
+	// Connectivity4 makes pixels neighbours if they share an edge.
+	Connectivity4 Connectivity = 4
+	// Connectivity8 makes pixels neighbours if they share an edge or a
+	// corner.
+	Connectivity8 Connectivity = 8
+)
+
+// A Component is a connected set of On pixels.
+type Component struct {
+	// Label is the label of the component pixels, starting at 1.
+	Label int
+	// Area is the number of pixels of the component.
+	Area int
+	// Bounds is the smallest rectangle containing the component.
+	Bounds image.Rectangle
+	// CentroidX and CentroidY are the mean coordinates of the component
+	// pixels.
+	CentroidX, CentroidY float64
+}
+
+// Labels is a label map, associating to each pixel of a binary image the
+// label of the connected component it belongs to.
+type Labels struct {
+	// Pix holds the label of each pixel, 0 for Off pixels. The label of the
+	// pixel at (x, y) is at Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)].
+	Pix []int32
+	// Stride is the Pix stride between vertically adjacent pixels.
+	Stride int
+	// Rect is the label map bounds.
+	Rect image.Rectangle
+	// Components holds the connected components, the component of label l
+	// being at index l-1.
+	Components []Component
+}
+
+// LabelAt returns the label of the pixel at (x, y), 0 for Off pixels and
+// pixels out of the map bounds.
+func (l *Labels) LabelAt(x, y int) int {
+	if !(image.Point{x, y}.In(l.Rect)) {
+		return 0
+	}
+	return int(l.Pix[(y-l.Rect.Min.Y)*l.Stride+(x-l.Rect.Min.X)])
+}
+
+// FilterArea removes the components whose area is not in [min, max], their
+// pixels being labelled 0. The remaining components are relabelled so that
+// labels stay consecutive.
+func (l *Labels) FilterArea(min, max int) {
+	relabel := make([]int32, len(l.Components)+1)
+	kept := l.Components[:0]
+	for _, c := range l.Components {
+		if c.Area < min || c.Area > max {
+			continue
+		}
+		relabel[c.Label] = int32(len(kept) + 1)
+		c.Label = len(kept) + 1
+		kept = append(kept, c)
+	}
+	l.Components = kept
+
+	for y := l.Rect.Min.Y; y < l.Rect.Max.Y; y++ {
+		i := (y - l.Rect.Min.Y) * l.Stride
+		row := l.Pix[i : i+l.Rect.Dx()]
+		for x, v := range row {
+			row[x] = relabel[v]
+		}
+	}
+}
+
+// Mask returns a binary image whose On pixels are the pixels of the
+// components of l.
+func (l *Labels) Mask() *Image {
+	b := New(l.Rect)
+	for y := l.Rect.Min.Y; y < l.Rect.Max.Y; y++ {
+		i := (y - l.Rect.Min.Y) * l.Stride
+		j := b.PixOffset(l.Rect.Min.X, y)
+		for _, v := range l.Pix[i : i+l.Rect.Dx()] {
+			if v != 0 {
+				b.Pix[j] = On.V
+			}
+			j++
+		}
+	}
+	return b
+}
+
+// Label labels the connected components of the On pixels of b, neighbours
+// being defined by conn.
+//
+// Labelling is performed in two passes over b, equivalences between
+// provisional labels being resolved with a union-find structure, so that
+// memory usage doesn't depend on the shape of the components.
+func Label(b *Image, conn Connectivity) *Labels {
+	r := b.Rect
+	w, h := r.Dx(), r.Dy()
+	l := &Labels{Rect: r, Stride: w}
+	if w <= 0 || h <= 0 {
+		return l
+	}
+	l.Pix = make([]int32, w*h)
+
+	// first pass: assign provisional labels, recording the equivalences
+	// of the labels of the already visited neighbours.
+	uf := unionFind{0}
+	for y := 0; y < h; y++ {
+		row := b.Pix[b.PixOffset(r.Min.X, r.Min.Y+y):]
+		for x := 0; x < w; x++ {
+			if row[x] == Off.V {
+				continue
+			}
+			i := y*w + x
+			var label int32
+			// visit the west, north-west, north and north-east neighbours
+			for _, n := range [...]struct {
+				dx, dy int
+				diag   bool
+			}{{-1, 0, false}, {-1, -1, true}, {0, -1, false}, {1, -1, true}} {
+				if n.diag && conn != Connectivity8 {
+					continue
+				}
+				nx, ny := x+n.dx, y+n.dy
+				if nx < 0 || nx >= w || ny < 0 {
+					continue
+				}
+				nl := l.Pix[ny*w+nx]
+				if nl == 0 {
+					continue
+				}
+				if label == 0 {
+					label = nl
+				} else {
+					label = uf.union(label, nl)
+				}
+			}
+			if label == 0 {
+				label = uf.add()
+			}
+			l.Pix[i] = label
+		}
+	}
+
+	// second pass: resolve the final labels, consecutive in the order of
+	// their first pixel, and compute the components stats.
+	final := make([]int32, len(uf))
+	var sumx, sumy []float64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			if l.Pix[i] == 0 {
+				continue
+			}
+			root := uf.find(l.Pix[i])
+			if final[root] == 0 {
+				l.Components = append(l.Components, Component{
+					Label:  len(l.Components) + 1,
+					Bounds: image.Rect(r.Min.X+x, r.Min.Y+y, r.Min.X+x+1, r.Min.Y+y+1),
+				})
+				sumx, sumy = append(sumx, 0), append(sumy, 0)
+				final[root] = int32(len(l.Components))
+			}
+			label := final[root]
+			l.Pix[i] = label
+
+			c := &l.Components[label-1]
+			c.Area++
+			c.Bounds = c.Bounds.Union(image.Rect(r.Min.X+x, r.Min.Y+y, r.Min.X+x+1, r.Min.Y+y+1))
+			sumx[label-1] += float64(r.Min.X + x)
+			sumy[label-1] += float64(r.Min.Y + y)
+		}
+	}
+	for i := range l.Components {
+		c := &l.Components[i]
+		c.CentroidX = sumx[i] / float64(c.Area)
+		c.CentroidY = sumy[i] / float64(c.Area)
+	}
+	return l
+}
+
+// unionFind is a disjoint-set forest of labels, the parent of label l being
+// at index l. Label 0 is unused.
+type unionFind []int32
+
+// add adds a new set and returns its label.
+func (uf *unionFind) add() int32 {
+	l := int32(len(*uf))
+	*uf = append(*uf, l)
+	return l
+}
+
+// find returns the root label of the set of l, compressing the path.
+func (uf unionFind) find(l int32) int32 {
+	root := l
+	for uf[root] != root {
+		root = uf[root]
+	}
+	for uf[l] != root {
+		uf[l], l = root, uf[l]
+	}
+	return root
+}
+
+// union merges the sets of a and b, and returns the root of the merged set.
+func (uf unionFind) union(a, b int32) int32 {
+	ra, rb := uf.find(a), uf.find(b)
+	if ra < rb {
+		uf[rb] = ra
+		return ra
+	}
+	uf[ra] = rb
+	return rb
+}
@@ -0,0 +1,135 @@
+package binimg
+
+import (
+	"image"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestLabel(t *testing.T) {
+	src := newFromString(image.Pt(-2, 3), []string{
+		"1100100",
+		"0101100",
+		"0111001",
+		"0000010",
+		"1000000",
+	})
+
+	var tests = []struct {
+		conn       Connectivity
+		components []Component
+	}{
+		{
+			Connectivity4,
+			[]Component{
+				{1, 9, image.Rect(-2, 3, 3, 6), 1. / 9, 4},
+				{2, 1, image.Rect(4, 5, 5, 6), 4, 5},
+				{3, 1, image.Rect(3, 6, 4, 7), 3, 6},
+				{4, 1, image.Rect(-2, 7, -1, 8), -2, 7},
+			},
+		},
+		{
+			Connectivity8,
+			[]Component{
+				{1, 9, image.Rect(-2, 3, 3, 6), 1. / 9, 4},
+				{2, 2, image.Rect(3, 5, 5, 7), 3.5, 5.5},
+				{3, 1, image.Rect(-2, 7, -1, 8), -2, 7},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		l := Label(src, tt.conn)
+		if len(l.Components) != len(tt.components) {
+			t.Fatalf("connectivity %d: want %d components, got %d: %v", tt.conn, len(tt.components), len(l.Components), l.Components)
+		}
+		for i, want := range tt.components {
+			got := l.Components[i]
+			if got.Label != want.Label || got.Area != want.Area || got.Bounds != want.Bounds ||
+				!almostEqual(got.CentroidX, want.CentroidX) || !almostEqual(got.CentroidY, want.CentroidY) {
+				t.Errorf("connectivity %d: want component %+v, got %+v", tt.conn, want, got)
+			}
+		}
+		// each pixel is labelled with its component
+		for y := src.Rect.Min.Y; y < src.Rect.Max.Y; y++ {
+			for x := src.Rect.Min.X; x < src.Rect.Max.X; x++ {
+				label := l.LabelAt(x, y)
+				if (label == 0) != (src.BitAt(x, y) == Off) {
+					t.Errorf("connectivity %d: wrong label %d for pixel %v at (%d,%d)", tt.conn, label, src.BitAt(x, y), x, y)
+				}
+				if label != 0 && !image.Pt(x, y).In(l.Components[label-1].Bounds) {
+					t.Errorf("connectivity %d: pixel (%d,%d) out of the bounds of its component %d", tt.conn, x, y, label)
+				}
+			}
+		}
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
+
+func TestLabelFilterArea(t *testing.T) {
+	src := newFromString(image.Pt(0, 0), []string{
+		"1100100",
+		"0101100",
+		"0111001",
+		"0000010",
+		"1000000",
+	})
+
+	l := Label(src, Connectivity4)
+	l.FilterArea(1, 1)
+	if len(l.Components) != 3 {
+		t.Fatalf("want 3 components, got %d", len(l.Components))
+	}
+	for i, c := range l.Components {
+		if c.Label != i+1 {
+			t.Errorf("want consecutive labels, got label %d at index %d", c.Label, i)
+		}
+	}
+
+	want := newFromString(image.Pt(0, 0), []string{
+		"0000000",
+		"0000000",
+		"0000001",
+		"0000010",
+		"1000000",
+	})
+	if err := test.Diff(want, l.Mask()); err != nil {
+		t.Errorf("filtered mask: %v", err)
+	}
+	if got := l.LabelAt(5, 3); got != 2 {
+		t.Errorf("want pixel (5,3) relabelled 2, got %d", got)
+	}
+}
+
+func TestLabelBigImage(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/big.png")
+	test.Check(t, err)
+
+	bin := NewFromImage(src)
+	l := Label(bin.SubImage(image.Rect(7, 9, 3000, 2000)).(*Image), Connectivity8)
+	if len(l.Components) == 0 {
+		t.Fatalf("want components in big image, got none")
+	}
+	var area int
+	for _, c := range l.Components {
+		area += c.Area
+	}
+	mask := l.Mask()
+	if err := test.Diff(bin.SubImage(mask.Rect), mask); err != nil {
+		t.Errorf("mask of all components differs from the original image: %v", err)
+	}
+	var on int
+	for _, v := range mask.Pix {
+		if v == On.V {
+			on++
+		}
+	}
+	if area != on {
+		t.Errorf("want total area %d, got %d", on, area)
+	}
+}
@@ -0,0 +1,53 @@
+package binimg
+
+import "image/color"
+
+// LumaWeights are the weights of the red, green and blue components used to
+// compute the luminance of a color. Only their relative values matter, the
+// luminance being normalized by the sum of the weights.
+type LumaWeights struct {
+	R, G, B uint32
+}
+
+// Predefined luma weights.
+var (
+	// Rec601 are the ITU-R BT.601 luma weights, used by Model.
+	Rec601 = LumaWeights{299, 587, 114}
+	// Rec709 are the ITU-R BT.709 luma weights.
+	Rec709 = LumaWeights{2126, 7152, 722}
+)
+
+// luma returns the 16-bit luminance of c, computed with the weights w.
+func (w LumaWeights) luma(c color.Color) uint32 {
+	r, g, b, _ := c.RGBA()
+	sum := uint64(w.R) + uint64(w.G) + uint64(w.B)
+	if sum == 0 {
+		return 0
+	}
+	y := uint64(w.R)*uint64(r) + uint64(w.G)*uint64(g) + uint64(w.B)*uint64(b)
+	return uint32((y + sum/2) / sum)
+}
+
+// thresholdModel converts colors to Bit by comparing their luminance to a
+// threshold.
+type thresholdModel struct {
+	threshold uint8
+	weights   LumaWeights
+}
+
+func (m *thresholdModel) Convert(c color.Color) color.Color {
+	if _, ok := c.(Bit); ok {
+		return c
+	}
+	if uint8(m.weights.luma(c)>>8) > m.threshold {
+		return White
+	}
+	return Black
+}
+
+// ThresholdModel returns a color model converting colors to Bit. Colors whose
+// 8-bit luminance, computed with the given weights, is greater than threshold
+// are converted to White, the others to Black.
+func ThresholdModel(threshold uint8, weights LumaWeights) color.Model {
+	return &thresholdModel{threshold, weights}
+}
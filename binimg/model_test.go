@@ -0,0 +1,67 @@
+package binimg
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestNewFromImageWithModel(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/colorgopher.png")
+	test.Check(t, err)
+
+	var tests = []struct {
+		model  color.Model
+		golden string
+	}{
+		{Model, "../testdata/bwgopher.png"},
+		{ThresholdModel(97, Rec601), "../testdata/bwgopher.png"},
+		{ThresholdModel(37, Rec601), "../testdata/bwgopher.low.threshold.png"},
+		{ThresholdModel(197, Rec601), "../testdata/bwgopher.high.threshold.png"},
+	}
+
+	for _, tt := range tests {
+		ref, err := test.LoadPNG(tt.golden)
+		test.Check(t, err)
+
+		bin := NewFromImageWithModel(src, tt.model)
+		if err := test.Diff(ref, bin); err != nil {
+			t.Errorf("converted image is different from %s: %v", tt.golden, err)
+		}
+	}
+}
+
+func TestThresholdModel(t *testing.T) {
+	var tests = []struct {
+		threshold uint8
+		weights   LumaWeights
+		col       color.Color
+		want      Bit
+	}{
+		{97, Rec601, color.Black, Black},
+		{97, Rec601, color.White, White},
+		{97, Rec601, White, White},
+		{255, Rec601, White, White},
+		{0, Rec601, Black, Black},
+		{255, Rec601, color.White, Black},
+		{0, Rec601, color.Gray{1}, White},
+		{0, Rec601, color.Gray{0}, Black},
+		{100, Rec601, color.Gray{100}, Black},
+		{100, Rec601, color.Gray{101}, White},
+		{100, Rec709, color.Gray{101}, White},
+		// pure green is brighter with Rec.709 weights
+		{160, Rec601, color.RGBA{0, 255, 0, 255}, Black},
+		{160, Rec709, color.RGBA{0, 255, 0, 255}, White},
+		{127, LumaWeights{1, 0, 0}, color.RGBA{255, 0, 0, 255}, White},
+		{127, LumaWeights{0, 0, 1}, color.RGBA{255, 0, 0, 255}, Black},
+		{127, LumaWeights{}, color.White, Black},
+	}
+
+	for _, tt := range tests {
+		got := ThresholdModel(tt.threshold, tt.weights).Convert(tt.col)
+		if got != tt.want {
+			t.Errorf("ThresholdModel(%d, %v).Convert(%v), want %v, got %v", tt.threshold, tt.weights, tt.col, tt.want, got)
+		}
+	}
+}
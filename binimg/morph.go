@@ -0,0 +1,156 @@
+package binimg
+
+import "image"
+
+// An Element is a structuring element, the shape used to probe binary images
+// in morphological operations.
+type Element struct {
+	// Kernel holds the shape of the element, made of its On pixels.
+	Kernel *Image
+	// Origin is the point of Kernel aligned with the processed pixel.
+	Origin image.Point
+
+	// rect is true if Kernel is a full rectangle.
+	rect bool
+}
+
+// NewElement returns a structuring element whose shape is made of the On
+// pixels of k, origin being the point of k aligned with the processed pixel.
+func NewElement(k *Image, origin image.Point) *Element {
+	return &Element{Kernel: k, Origin: origin}
+}
+
+// RectElement returns a w x h rectangular structuring element, centered on
+// its origin.
+func RectElement(w, h int) *Element {
+	k := New(image.Rect(0, 0, w, h))
+	k.SetRect(k.Rect, On)
+	return &Element{Kernel: k, Origin: image.Pt(w/2, h/2), rect: true}
+}
+
+// CrossElement returns a cross-shaped structuring element, made of the
+// middle line and column of a size x size square, centered on its origin.
+func CrossElement(size int) *Element {
+	k := New(image.Rect(0, 0, size, size))
+	k.SetRect(image.Rect(0, size/2, size, size/2+1), On)
+	k.SetRect(image.Rect(size/2, 0, size/2+1, size), On)
+	return &Element{Kernel: k, Origin: image.Pt(size/2, size/2)}
+}
+
+// DiskElement returns a disk-shaped structuring element of given radius,
+// centered on its origin.
+func DiskElement(radius int) *Element {
+	k := New(image.Rect(-radius, -radius, radius+1, radius+1))
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				k.SetBit(x, y, On)
+			}
+		}
+	}
+	return &Element{Kernel: k}
+}
+
+// offsets returns the offsets, relatively to the origin, of the points of e.
+func (e *Element) offsets() []image.Point {
+	var offs []image.Point
+	r := e.Kernel.Rect
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if e.Kernel.BitAt(x, y) == On {
+				offs = append(offs, image.Pt(x, y).Sub(e.Origin))
+			}
+		}
+	}
+	return offs
+}
+
+// Erode returns the erosion of src by the structuring element e: a pixel is
+// On if all the pixels of src covered by e, placed at its origin on that
+// pixel, are On. Pixels outside of the bounds of src are ignored.
+//
+// The returned image has the same bounds and palette as src.
+func Erode(src *Image, e *Element) *Image {
+	return morph(src, e, true)
+}
+
+// Dilate returns the dilation of src by the structuring element e: a pixel
+// is On if any pixel of src covered by the reflection of e, placed at its
+// origin on that pixel, is On. Pixels outside of the bounds of src are
+// ignored.
+//
+// The returned image has the same bounds and palette as src.
+func Dilate(src *Image, e *Element) *Image {
+	return morph(src, e, false)
+}
+
+// Open returns the opening of src by the structuring element e, that is the
+// dilation of the erosion of src. It removes the On shapes of src, or parts
+// of them, that e can't fit in.
+func Open(src *Image, e *Element) *Image {
+	return Dilate(Erode(src, e), e)
+}
+
+// Close returns the closing of src by the structuring element e, that is the
+// erosion of the dilation of src. It fills the Off holes and gaps of src that
+// e can't fit in.
+func Close(src *Image, e *Element) *Image {
+	return Erode(Dilate(src, e), e)
+}
+
+// morph performs the erosion, or dilation, of src by e.
+func morph(src *Image, e *Element, erode bool) *Image {
+	if e.rect {
+		// a rectangle is separable in an horizontal and a vertical line
+		r := e.Kernel.Rect.Sub(e.Origin)
+		var hoffs, voffs []image.Point
+		for x := r.Min.X; x < r.Max.X; x++ {
+			hoffs = append(hoffs, image.Pt(x, 0))
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			voffs = append(voffs, image.Pt(0, y))
+		}
+		return morphOffsets(morphOffsets(src, hoffs, erode), voffs, erode)
+	}
+	return morphOffsets(src, e.offsets(), erode)
+}
+
+// morphOffsets performs the erosion, or dilation, of src by the structuring
+// element made of the points at offsets offs of its origin.
+func morphOffsets(src *Image, offs []image.Point, erode bool) *Image {
+	dst := NewWithPalette(src.Rect, src.Palette)
+	if erode {
+		dst.SetRect(dst.Rect, On)
+	}
+	b := src.Rect
+	for _, d := range offs {
+		if !erode {
+			// dilation uses the reflected structuring element
+			d = image.Pt(-d.X, -d.Y)
+		}
+		// the pixels of dst whose shifted position is in src
+		r := b.Intersect(b.Sub(d))
+		if r.Empty() {
+			continue
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			si := src.PixOffset(r.Min.X+d.X, y+d.Y)
+			di := dst.PixOffset(r.Min.X, y)
+			srow, drow := src.Pix[si:si+r.Dx()], dst.Pix[di:di+r.Dx()]
+			if erode {
+				for i, v := range srow {
+					if v == Off.V {
+						drow[i] = Off.V
+					}
+				}
+			} else {
+				for i, v := range srow {
+					if v != Off.V {
+						drow[i] = On.V
+					}
+				}
+			}
+		}
+	}
+	return dst
+}
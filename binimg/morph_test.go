@@ -0,0 +1,158 @@
+package binimg
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+// newFromString creates a binary image from a slice of strings of '0' and
+// '1', the top-left pixel being at min.
+func newFromString(min image.Point, ss []string) *Image {
+	b := New(image.Rectangle{min, min.Add(image.Pt(len(ss[0]), len(ss)))})
+	for y := range ss {
+		for x := range ss[y] {
+			if ss[y][x] == '1' {
+				b.SetBit(min.X+x, min.Y+y, On)
+			}
+		}
+	}
+	return b
+}
+
+// naiveMorph is the straightforward implementation of the erosion, or
+// dilation, of src by e.
+func naiveMorph(src *Image, e *Element, erode bool) *Image {
+	dst := New(src.Rect)
+	for y := src.Rect.Min.Y; y < src.Rect.Max.Y; y++ {
+		for x := src.Rect.Min.X; x < src.Rect.Max.X; x++ {
+			hit, fit := false, true
+			for _, d := range e.offsets() {
+				p := image.Pt(x+d.X, y+d.Y)
+				if !erode {
+					p = image.Pt(x-d.X, y-d.Y)
+				}
+				if !p.In(src.Rect) {
+					continue
+				}
+				if src.BitAt(p.X, p.Y) == On {
+					hit = true
+				} else {
+					fit = false
+				}
+			}
+			if (erode && fit) || (!erode && hit) {
+				dst.SetBit(x, y, On)
+			}
+		}
+	}
+	return dst
+}
+
+func TestMorph(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	big := New(image.Rect(-7, 3, 53, 47))
+	for i := range big.Pix {
+		if rnd.Intn(3) != 0 {
+			big.Pix[i] = On.V
+		}
+	}
+	src := big.SubImage(image.Rect(-5, 5, 45, 40)).(*Image)
+
+	custom := newFromString(image.Pt(2, -1), []string{
+		"110",
+		"011",
+		"001",
+	})
+	elements := map[string]*Element{
+		"rect 1x1":  RectElement(1, 1),
+		"rect 3x3":  RectElement(3, 3),
+		"rect 4x2":  RectElement(4, 2),
+		"rect 1x7":  RectElement(1, 7),
+		"cross 3":   CrossElement(3),
+		"cross 5":   CrossElement(5),
+		"disk 1":    DiskElement(1),
+		"disk 3":    DiskElement(3),
+		"custom":    NewElement(custom, image.Pt(3, 0)),
+		"off-shape": NewElement(custom, image.Pt(-2, 4)),
+	}
+
+	for name, e := range elements {
+		if err := test.Diff(naiveMorph(src, e, true), Erode(src, e)); err != nil {
+			t.Errorf("Erode(%s): %v", name, err)
+		}
+		if err := test.Diff(naiveMorph(src, e, false), Dilate(src, e)); err != nil {
+			t.Errorf("Dilate(%s): %v", name, err)
+		}
+		if got := Erode(src, e).Rect; got != src.Rect {
+			t.Errorf("Erode(%s): want bounds %v, got %v", name, src.Rect, got)
+		}
+	}
+}
+
+func TestMorphRectSeparable(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/bwgopher.png")
+	test.Check(t, err)
+	bin := NewFromImage(src)
+
+	for _, sz := range []image.Point{{1, 1}, {3, 3}, {5, 2}, {8, 8}} {
+		rect := RectElement(sz.X, sz.Y)
+		generic := NewElement(rect.Kernel, rect.Origin)
+		if err := test.Diff(Erode(bin, generic), Erode(bin, rect)); err != nil {
+			t.Errorf("Erode(rect %v): separable and generic differ: %v", sz, err)
+		}
+		if err := test.Diff(Dilate(bin, generic), Dilate(bin, rect)); err != nil {
+			t.Errorf("Dilate(rect %v): separable and generic differ: %v", sz, err)
+		}
+	}
+}
+
+func TestOpenClose(t *testing.T) {
+	src := newFromString(image.Pt(10, 10), []string{
+		"0000000000",
+		"0000000000",
+		"0010000000",
+		"0000000000",
+		"0000111110",
+		"0000111110",
+		"0000110110",
+		"0000111110",
+		"0000000000",
+	})
+
+	// isolated pixel removed, as well as the pixel below the hole, that
+	// can't be covered by a 2x2 square.
+	opened := newFromString(image.Pt(10, 10), []string{
+		"0000000000",
+		"0000000000",
+		"0000000000",
+		"0000000000",
+		"0000111110",
+		"0000111110",
+		"0000110110",
+		"0000110110",
+		"0000000000",
+	})
+	// hole filled
+	closed := newFromString(image.Pt(10, 10), []string{
+		"0000000000",
+		"0000000000",
+		"0010000000",
+		"0000000000",
+		"0000111110",
+		"0000111110",
+		"0000111110",
+		"0000111110",
+		"0000000000",
+	})
+
+	e := RectElement(2, 2)
+	if err := test.Diff(opened, Open(src, e)); err != nil {
+		t.Errorf("Open: %v", err)
+	}
+	if err := test.Diff(closed, Close(src, e)); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
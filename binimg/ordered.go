@@ -0,0 +1,118 @@
+package binimg
+
+import (
+	"fmt"
+	"image"
+)
+
+// A ThresholdMatrix is a square matrix of thresholds, tiled over the image
+// plane by ordered dithering.
+type ThresholdMatrix struct {
+	// Size is the side of the matrix.
+	Size int
+	// Values holds the Size*Size thresholds, line after line. Each threshold
+	// is a 16-bit luminance, a pixel being On if its luminance is greater
+	// than the threshold.
+	Values []uint16
+}
+
+// Bayer returns the Bayer threshold matrix of side n, which must be 2, 4, 8
+// or 16. An error is returned for other values.
+func Bayer(n int) (*ThresholdMatrix, error) {
+	switch n {
+	case 2, 4, 8, 16:
+	default:
+		return nil, fmt.Errorf("binimg: invalid Bayer matrix size %d, want 2, 4, 8 or 16", n)
+	}
+
+	// build the index matrix recursively, M(2n) being made of the 4
+	// quadrants 4*M(n)+0, 4*M(n)+2, 4*M(n)+3 and 4*M(n)+1.
+	idx := []int{0}
+	for size := 1; size < n; size *= 2 {
+		next := make([]int, 4*size*size)
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				v := 4 * idx[y*size+x]
+				next[y*2*size+x] = v
+				next[y*2*size+x+size] = v + 2
+				next[(y+size)*2*size+x] = v + 3
+				next[(y+size)*2*size+x+size] = v + 1
+			}
+		}
+		idx = next
+	}
+	return NewThresholdMatrix(n, idx)
+}
+
+// NewThresholdMatrix returns the threshold matrix of side n whose thresholds
+// are evenly distributed over the luminance range in the order given by idx,
+// a permutation of the n*n first integers, line after line. For example, a
+// blue-noise matrix can be created from the ranks of a blue-noise mask.
+//
+// An error is returned if n is not positive or if idx is not a permutation of
+// the n*n first integers.
+func NewThresholdMatrix(n int, idx []int) (*ThresholdMatrix, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("binimg: invalid threshold matrix size %d", n)
+	}
+	levels := n * n
+	if len(idx) != levels {
+		return nil, fmt.Errorf("binimg: threshold matrix of size %d has %d indices, want %d", n, len(idx), levels)
+	}
+	seen := make([]bool, levels)
+	for i, rank := range idx {
+		if rank < 0 || rank >= levels || seen[rank] {
+			return nil, fmt.Errorf("binimg: threshold matrix index %d at position %d is out of range or duplicated", rank, i)
+		}
+		seen[rank] = true
+	}
+
+	m := &ThresholdMatrix{Size: n, Values: make([]uint16, levels)}
+	for i, rank := range idx {
+		// the threshold of rank r is centered on the r-th of n*n equal
+		// subdivisions of the luminance range
+		m.Values[i] = uint16((2*rank + 1) * 0xffff / (2 * levels))
+	}
+	return m, nil
+}
+
+// at returns the threshold of the pixel at absolute coordinates (x, y).
+func (m *ThresholdMatrix) at(x, y int) uint16 {
+	mx, my := x%m.Size, y%m.Size
+	if mx < 0 {
+		mx += m.Size
+	}
+	if my < 0 {
+		my += m.Size
+	}
+	return m.Values[my*m.Size+mx]
+}
+
+// NewOrdered returns a new binary image that is the conversion of src image
+// using ordered dithering with the threshold matrix m: a pixel is On if its
+// Rec.601 luminance is greater than the threshold of the matrix, tiled over
+// the image plane.
+//
+// The matrix is anchored to absolute image coordinates, the pixel at (0, 0)
+// using the top-left threshold. Converting the sub-images of src therefore
+// produces the same pixels as converting src as a whole.
+//
+// NewOrdered panics if m is nil, if its Size is not positive, or if it doesn't
+// hold Size*Size values.
+func NewOrdered(src image.Image, m *ThresholdMatrix) *Image {
+	if m == nil || m.Size <= 0 || len(m.Values) != m.Size*m.Size {
+		panic("binimg: invalid threshold matrix")
+	}
+	b := src.Bounds()
+	dst := New(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := dst.PixOffset(b.Min.X, y)
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if Rec601.luma(src.At(x, y)) > uint32(m.at(x, y)) {
+				dst.Pix[i] = On.V
+			}
+			i++
+		}
+	}
+	return dst
+}
@@ -0,0 +1,167 @@
+package binimg
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestBayer(t *testing.T) {
+	want4 := []int{
+		0, 8, 2, 10,
+		12, 4, 14, 6,
+		3, 11, 1, 9,
+		15, 7, 13, 5,
+	}
+	if got := mustBayer(t, 4); got.Size != 4 || len(got.Values) != 16 {
+		t.Fatalf("want 4x4 matrix, got size %d with %d values", got.Size, len(got.Values))
+	}
+	want, err := NewThresholdMatrix(4, want4)
+	test.Check(t, err)
+	if got := mustBayer(t, 4); !equalThresholds(got, want) {
+		t.Errorf("want Bayer(4) = %v, got %v", want.Values, got.Values)
+	}
+
+	for _, n := range []int{2, 4, 8, 16} {
+		m := mustBayer(t, n)
+		// all the thresholds are different
+		seen := make(map[uint16]bool)
+		for _, v := range m.Values {
+			if seen[v] {
+				t.Errorf("Bayer(%d) has duplicate threshold %d", n, v)
+			}
+			seen[v] = true
+		}
+	}
+
+	for _, n := range []int{-1, 0, 1, 3, 32} {
+		if m, err := Bayer(n); err == nil {
+			t.Errorf("want Bayer(%d) error, got matrix %v", n, m)
+		}
+	}
+}
+
+// mustBayer returns the Bayer matrix of side n, failing the test on error.
+func mustBayer(tb testing.TB, n int) *ThresholdMatrix {
+	m, err := Bayer(n)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return m
+}
+
+func equalThresholds(m0, m1 *ThresholdMatrix) bool {
+	if m0.Size != m1.Size || len(m0.Values) != len(m1.Values) {
+		return false
+	}
+	for i := range m0.Values {
+		if m0.Values[i] != m1.Values[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestOrderedDensity(t *testing.T) {
+	for _, n := range []int{2, 4, 8, 16} {
+		for level := 0; level < 256; level += 17 {
+			src := image.NewUniform(color.Gray{uint8(level)})
+			bin := NewOrdered(&clipped{src, image.Rect(-n, -n, 3*n, 3*n)}, mustBayer(t, n))
+
+			// each tile of the matrix has exactly level*n*n/255 On pixels
+			want := float64(level) / 255
+			got := onDensity(bin)
+			if tolerance := 1 / float64(n*n); got < want-tolerance || got > want+tolerance {
+				t.Errorf("Bayer(%d): want density of %v for level %d, got %v", n, want, level, got)
+			}
+		}
+	}
+}
+
+// clipped restricts the bounds of an image.
+type clipped struct {
+	image.Image
+	r image.Rectangle
+}
+
+func (c *clipped) Bounds() image.Rectangle { return c.r }
+
+func TestNewThresholdMatrixInvalid(t *testing.T) {
+	var tests = []struct {
+		n   int
+		idx []int
+	}{
+		{0, nil},
+		{-2, []int{0, 1, 2, 3}},
+		{2, []int{0, 1, 2}},       // too short
+		{2, []int{0, 1, 2, 3, 4}}, // too long
+		{2, []int{0, 1, 2, 4}},    // out of range
+		{2, []int{0, 1, -1, 3}},   // negative
+		{2, []int{0, 1, 1, 3}},    // duplicated
+	}
+	for _, tt := range tests {
+		if m, err := NewThresholdMatrix(tt.n, tt.idx); err == nil {
+			t.Errorf("NewThresholdMatrix(%d, %v): want error, got matrix %v", tt.n, tt.idx, m)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("want NewOrdered to panic with an invalid matrix")
+		}
+	}()
+	NewOrdered(New(image.Rect(0, 0, 4, 4)), &ThresholdMatrix{})
+}
+
+func TestOrderedNilMatrix(t *testing.T) {
+	defer func() {
+		if recover() != "binimg: invalid threshold matrix" {
+			t.Errorf("want NewOrdered to panic with an invalid matrix message")
+		}
+	}()
+	NewOrdered(New(image.Rect(0, 0, 4, 4)), nil)
+}
+
+func TestOrderedTiles(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/colorgopher.png")
+	test.Check(t, err)
+
+	custom, err := NewThresholdMatrix(3, []int{
+		4, 1, 7,
+		2, 0, 6,
+		8, 5, 3,
+	})
+	test.Check(t, err)
+	for _, m := range []*ThresholdMatrix{mustBayer(t, 8), custom} {
+		whole := NewOrdered(src, m)
+
+		// tiles with sizes unrelated to the matrix size
+		const tile = 37
+		b := src.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y += tile {
+			for x := b.Min.X; x < b.Max.X; x += tile {
+				r := image.Rect(x, y, x+tile, y+tile).Intersect(b)
+				sub := src.(interface {
+					SubImage(image.Rectangle) image.Image
+				}).SubImage(r)
+				if err := test.Diff(whole.SubImage(r), NewOrdered(sub, m)); err != nil {
+					t.Fatalf("tile %v differs from whole image conversion: %v", r, err)
+				}
+			}
+		}
+	}
+}
+
+func TestOrderedBlackAndWhite(t *testing.T) {
+	r := image.Rect(-5, -5, 5, 5)
+	black := NewOrdered(&clipped{image.NewUniform(color.Black), r}, mustBayer(t, 16))
+	white := NewOrdered(&clipped{image.NewUniform(color.White), r}, mustBayer(t, 16))
+	if d := onDensity(black); d != 0 {
+		t.Errorf("want black image to be all Off, got density %v", d)
+	}
+	if d := onDensity(white); d != 1 {
+		t.Errorf("want white image to be all On, got density %v", d)
+	}
+}
@@ -0,0 +1,89 @@
+package binimg
+
+import "image"
+
+// OtsuThreshold returns the threshold computed with Otsu's method on the
+// Rec.601 luminance of src, that is the threshold maximizing the
+// between-class variance of the pixels whose luminance is lower or equal to
+// it and the pixels whose luminance is greater than it.
+//
+// The returned threshold is meant to be used with ThresholdModel.
+func OtsuThreshold(src image.Image) uint8 {
+	return otsu(lumaHistogram(src))
+}
+
+// NewOtsu returns a new binary image that is the conversion of src image,
+// using the threshold computed by OtsuThreshold.
+func NewOtsu(src image.Image) *Image {
+	t := OtsuThreshold(src)
+	if gray, ok := src.(*image.Gray); ok {
+		dst := New(gray.Rect)
+		for y := gray.Rect.Min.Y; y < gray.Rect.Max.Y; y++ {
+			i := gray.PixOffset(gray.Rect.Min.X, y)
+			j := dst.PixOffset(gray.Rect.Min.X, y)
+			for _, v := range gray.Pix[i : i+gray.Rect.Dx()] {
+				if v > t {
+					dst.Pix[j] = On.V
+				}
+				j++
+			}
+		}
+		return dst
+	}
+	return NewFromImageWithModel(src, ThresholdModel(t, Rec601))
+}
+
+// lumaHistogram returns the histogram of the 8-bit Rec.601 luminance of the
+// pixels of src.
+func lumaHistogram(src image.Image) *[256]int {
+	var hist [256]int
+	b := src.Bounds()
+	if gray, ok := src.(*image.Gray); ok {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			i := gray.PixOffset(b.Min.X, y)
+			for _, v := range gray.Pix[i : i+b.Dx()] {
+				hist[v]++
+			}
+		}
+		return &hist
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			hist[Rec601.luma(src.At(x, y))>>8]++
+		}
+	}
+	return &hist
+}
+
+// otsu returns the threshold maximizing the between-class variance of hist.
+// In case of equality, the lowest threshold is returned.
+func otsu(hist *[256]int) uint8 {
+	var total, sum float64
+	for i, n := range hist {
+		total += float64(n)
+		sum += float64(i * n)
+	}
+
+	var (
+		best     uint8
+		bestVar  float64
+		w0, sum0 float64 // weight and luminance sum of the lower class
+	)
+	for t := 0; t < 256; t++ {
+		w0 += float64(hist[t])
+		sum0 += float64(t * hist[t])
+		if w0 == 0 {
+			continue
+		}
+		w1 := total - w0
+		if w1 == 0 {
+			break
+		}
+		mu0, mu1 := sum0/w0, (sum-sum0)/w1
+		v := w0 * w1 * (mu0 - mu1) * (mu0 - mu1)
+		if v > bestVar {
+			best, bestVar = uint8(t), v
+		}
+	}
+	return best
+}
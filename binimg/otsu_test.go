@@ -0,0 +1,97 @@
+package binimg
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestOtsuThreshold(t *testing.T) {
+	// bimodal gray image, with 2 values around each mode
+	gray := image.NewGray(image.Rect(0, 0, 10, 10))
+	for i := range gray.Pix {
+		switch i % 4 {
+		case 0:
+			gray.Pix[i] = 40
+		case 1:
+			gray.Pix[i] = 60
+		case 2:
+			gray.Pix[i] = 190
+		case 3:
+			gray.Pix[i] = 210
+		}
+	}
+
+	var tests = []struct {
+		img  image.Image
+		want uint8
+	}{
+		{image.NewGray(image.Rect(0, 0, 10, 10)), 0},
+		{gray, 60},
+		{gray.SubImage(image.Rect(0, 0, 2, 1)), 40},
+	}
+
+	for _, tt := range tests {
+		if got := OtsuThreshold(tt.img); got != tt.want {
+			t.Errorf("OtsuThreshold(%T %v), want %d, got %d", tt.img, tt.img.Bounds(), tt.want, got)
+		}
+	}
+}
+
+func TestOtsuHistogram(t *testing.T) {
+	// one pixel of each of the luminances 0, 100, 110 and 255. The
+	// between-class variances w0*w1*(mu0-mu1)^2 of the thresholds 0, 100 and
+	// 110 are respectively 1*3*155^2 = 72075, 2*2*132.5^2 = 70225 and
+	// 3*1*185^2 = 102675.
+	var hist [256]int
+	hist[0], hist[100], hist[110], hist[255] = 1, 1, 1, 1
+	if got := otsu(&hist); got != 110 {
+		t.Errorf("want threshold 110, got %d", got)
+	}
+
+	// 3 pixels of luminance 10, 1 of 20 and 2 of 200: all the thresholds
+	// from 20 to 199 separate the same classes, the lowest is returned.
+	hist = [256]int{}
+	hist[10], hist[20], hist[200] = 3, 1, 2
+	if got := otsu(&hist); got != 20 {
+		t.Errorf("want threshold 20, got %d", got)
+	}
+}
+
+func TestNewOtsu(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/colorgopher.png")
+	test.Check(t, err)
+
+	// known Otsu threshold of the Rec.601 luminance of colorgopher.png
+	const th = 97
+	if got := OtsuThreshold(src); got != th {
+		t.Fatalf("want threshold %d, got %d", th, got)
+	}
+	ref := NewFromImageWithModel(src, ThresholdModel(th, Rec601))
+	bin := NewOtsu(src)
+	if err := test.Diff(ref, bin); err != nil {
+		t.Errorf("NewOtsu differs from conversion with threshold %d: %v", th, err)
+	}
+
+	// the gray fast path gives the same results
+	gray := image.NewGray(src.Bounds())
+	for y := gray.Rect.Min.Y; y < gray.Rect.Max.Y; y++ {
+		for x := gray.Rect.Min.X; x < gray.Rect.Max.X; x++ {
+			gray.SetGray(x, y, color.Gray{uint8(Rec601.luma(src.At(x, y)) >> 8)})
+		}
+	}
+	if gth := OtsuThreshold(gray); gth != th {
+		t.Errorf("want gray image threshold %d, got %d", th, gth)
+	}
+	if err := test.Diff(bin, NewOtsu(gray)); err != nil {
+		t.Errorf("NewOtsu of gray image differs: %v", err)
+	}
+
+	sub := gray.SubImage(image.Rect(352, 352, 480, 480))
+	ref = NewFromImageWithModel(sub, ThresholdModel(OtsuThreshold(sub), Rec601))
+	if err := test.Diff(ref, NewOtsu(sub)); err != nil {
+		t.Errorf("NewOtsu of gray sub-image differs: %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package binimg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Packed is an in-memory binary image whose At method returns Bit values.
+//
+// Contrary to Image, Packed stores 8 pixels per byte, reducing the memory
+// footprint by a factor of 8, at the cost of slower pixel access.
+type Packed struct {
+	// Pix holds the image's pixels, 8 pixels per byte, the most significant
+	// bit being the leftmost pixel. A set bit represents an On pixel. Bytes
+	// are aligned on absolute x coordinates, so that the pixel at (x, y) is
+	// the bit 7-(x&7) of Pix[(y-Rect.Min.Y)*Stride + (x>>3) - (Rect.Min.X>>3)].
+	Pix []uint8
+	// Stride is the Pix stride (in bytes) between vertically adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+	// Palette is the image's palette. If nil, On and Off pixels are
+	// respectively rendered as White and Black.
+	Palette *Palette
+}
+
+// ColorModel returns the image.Image's color model.
+func (p *Packed) ColorModel() color.Model {
+	if p.Palette != nil {
+		return p.Palette
+	}
+	return Model
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+// The bounds do not necessarily contain the point (0, 0).
+func (p *Packed) Bounds() image.Rectangle { return p.Rect }
+
+// At returns the color of the pixel at (x, y).
+// At(Bounds().Min.X, Bounds().Min.Y) returns the upper-left pixel of the grid.
+// At(Bounds().Max.X-1, Bounds().Max.Y-1) returns the lower-right one.
+func (p *Packed) At(x, y int) color.Color {
+	return p.Palette.Color(p.BitAt(x, y))
+}
+
+// BitAt returns the Bit color of the pixel at (x, y).
+// BitAt(Bounds().Min.X, Bounds().Min.Y) returns the upper-left pixel of the
+// grid. BitAt(Bounds().Max.X-1, Bounds().Max.Y-1) returns the lower-right
+// one.
+func (p *Packed) BitAt(x, y int) Bit {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return Bit{}
+	}
+	i := p.PixOffset(x, y)
+	if p.Pix[i]&bitMask(x) != 0 {
+		return On
+	}
+	return Off
+}
+
+// PixOffset returns the index of the element of Pix that contains the pixel
+// at (x, y).
+func (p *Packed) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x >> 3) - (p.Rect.Min.X >> 3)
+}
+
+// Set sets the color of the pixel at (x, y).
+//
+// c is converted to Bit using the image palette.
+func (p *Packed) Set(x, y int, c color.Color) {
+	p.SetBit(x, y, p.Palette.Bit(c))
+}
+
+// SetBit sets the Bit of the pixel at (x, y).
+func (p *Packed) SetBit(x, y int, c Bit) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	i := p.PixOffset(x, y)
+	if c == Off {
+		p.Pix[i] &^= bitMask(x)
+	} else {
+		p.Pix[i] |= bitMask(x)
+	}
+}
+
+// SetRect sets all the pixels in the rectangle defined by given rectangle.
+func (p *Packed) SetRect(r image.Rectangle, c Bit) {
+	r = r.Intersect(p.Rect)
+	if r.Empty() {
+		return
+	}
+	// masks of the bits to modify in the first and last byte of each line
+	first, last := r.Min.X>>3, (r.Max.X-1)>>3
+	head, tail := uint8(0xff>>uint(r.Min.X&7)), uint8(0xff<<uint(7-((r.Max.X-1)&7)))
+	if first == last {
+		head &= tail
+		tail = head
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := p.PixOffset(r.Min.X, y)
+		j := i + last - first
+		if c == Off {
+			p.Pix[i] &^= head
+			p.Pix[j] &^= tail
+		} else {
+			p.Pix[i] |= head
+			p.Pix[j] |= tail
+		}
+		// loop on the bytes fully contained in this horizontal line
+		for k := i + 1; k < j; k++ {
+			if c == Off {
+				p.Pix[k] = 0
+			} else {
+				p.Pix[k] = 0xff
+			}
+		}
+	}
+}
+
+// SubImage returns an image representing the portion of the image p visible
+// through r. The returned value shares pixels with the original image.
+func (p *Packed) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	// If r1 and r2 are Rectangles, r1.Intersect(r2) is not guaranteed to be inside
+	// either r1 or r2 if the intersection is empty. Without explicitly checking for
+	// this, the Pix[i:] expression below can panic.
+	if r.Empty() {
+		return &Packed{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &Packed{
+		Pix:     p.Pix[i:],
+		Stride:  p.Stride,
+		Rect:    r,
+		Palette: p.Palette,
+	}
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (p *Packed) Opaque() bool {
+	if p.Palette.isOpaque(On) && p.Palette.isOpaque(Off) {
+		return true
+	}
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for x := p.Rect.Min.X; x < p.Rect.Max.X; x++ {
+			if !p.Palette.isOpaque(p.BitAt(x, y)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// bitMask returns the mask selecting the bit of pixel at x in its byte.
+func bitMask(x int) uint8 {
+	return 0x80 >> uint(x&7)
+}
+
+// packedStride returns the number of bytes needed to store a line of pixels
+// of r in a packed image.
+func packedStride(r image.Rectangle) int {
+	if r.Dx() <= 0 {
+		return 0
+	}
+	return (r.Max.X-1)>>3 - r.Min.X>>3 + 1
+}
+
+// NewPacked returns a new packed binary image with the given bounds.
+func NewPacked(r image.Rectangle) *Packed {
+	stride := packedStride(r)
+	pix := make([]uint8, stride*r.Dy())
+	return &Packed{pix, stride, r, nil}
+}
+
+// NewPackedFromImage returns a new packed binary image that is the
+// conversion of src image.
+func NewPackedFromImage(src image.Image) *Packed {
+	if b, ok := src.(*Image); ok {
+		return Pack(b)
+	}
+	dst := NewPacked(src.Bounds())
+	draw.Draw(dst, dst.Bounds(), src, src.Bounds().Min, draw.Src)
+	return dst
+}
+
+// Pack returns a new packed binary image holding the same pixels, and
+// sharing the palette, of b.
+func Pack(b *Image) *Packed {
+	p := NewPacked(b.Rect)
+	p.Palette = b.Palette
+	for y := b.Rect.Min.Y; y < b.Rect.Max.Y; y++ {
+		i := b.PixOffset(b.Rect.Min.X, y)
+		j := p.PixOffset(b.Rect.Min.X, y)
+		for x := b.Rect.Min.X; x < b.Rect.Max.X; x++ {
+			if b.Pix[i] != Off.V {
+				p.Pix[j] |= bitMask(x)
+			}
+			i++
+			if x&7 == 7 {
+				j++
+			}
+		}
+	}
+	return p
+}
+
+// Unpack returns a new byte-per-pixel binary image holding the same pixels,
+// and sharing the palette, of p.
+func Unpack(p *Packed) *Image {
+	b := NewWithPalette(p.Rect, p.Palette)
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		i := b.PixOffset(p.Rect.Min.X, y)
+		j := p.PixOffset(p.Rect.Min.X, y)
+		for x := p.Rect.Min.X; x < p.Rect.Max.X; x++ {
+			if p.Pix[j]&bitMask(x) != 0 {
+				b.Pix[i] = On.V
+			}
+			i++
+			if x&7 == 7 {
+				j++
+			}
+		}
+	}
+	return b
+}
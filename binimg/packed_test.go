@@ -0,0 +1,149 @@
+package binimg
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestPackedFromImage(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/colorgopher.png")
+	test.Check(t, err)
+
+	packed := NewPackedFromImage(src)
+	refname := "../testdata/bwgopher.png"
+	ref, err := test.LoadPNG(refname)
+	test.Check(t, err)
+
+	err = test.Diff(ref, packed)
+	if err != nil {
+		t.Errorf("converted image is different from %s: %v", refname, err)
+	}
+	if len(packed.Pix) != packed.Stride*packed.Rect.Dy() {
+		t.Errorf("want len(Pix) = %d, got %d", packed.Stride*packed.Rect.Dy(), len(packed.Pix))
+	}
+}
+
+func TestPackUnpack(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/colorgopher.png")
+	test.Check(t, err)
+
+	var tests = []image.Rectangle{
+		image.Rect(0, 0, 512, 512),
+		image.Rect(3, 5, 17, 23),
+		image.Rect(352, 352, 480, 480),
+		image.Rect(9, 1, 10, 2),
+	}
+
+	bin := NewFromImage(src)
+	for _, r := range tests {
+		sub := bin.SubImage(r).(*Image)
+		packed := Pack(sub)
+		if err := test.Diff(sub, packed); err != nil {
+			t.Errorf("Pack(%v) differs from original: %v", r, err)
+		}
+		unpacked := Unpack(packed)
+		if unpacked.Rect != sub.Rect {
+			t.Errorf("want Unpack bounds %v, got %v", sub.Rect, unpacked.Rect)
+		}
+		if err := test.Diff(sub, unpacked); err != nil {
+			t.Errorf("Unpack(Pack(%v)) differs from original: %v", r, err)
+		}
+	}
+}
+
+func TestPackedSubImage(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/colorgopher.png")
+	test.Check(t, err)
+
+	sub := NewPackedFromImage(src).SubImage(image.Rect(352, 352, 480, 480))
+	refname := "../testdata/bwgopher.bottom-left.png"
+	ref, err := test.LoadPNG(refname)
+	test.Check(t, err)
+
+	err = test.Diff(ref, sub)
+	if err != nil {
+		t.Errorf("converted image is different from %s: %v", refname, err)
+	}
+
+	// unaligned sub-image shares pixels with the original image
+	packed := NewPacked(image.Rect(-5, -5, 20, 20))
+	unaligned := packed.SubImage(image.Rect(3, 2, 11, 4)).(*Packed)
+	unaligned.SetBit(3, 2, On)
+	unaligned.SetBit(10, 3, On)
+	if packed.BitAt(3, 2) != On || packed.BitAt(10, 3) != On {
+		t.Errorf("want sub-image to share pixels with the original image")
+	}
+	if packed.BitAt(2, 2) != Off || packed.BitAt(11, 3) != Off {
+		t.Errorf("want pixels out of sub-image to be unmodified")
+	}
+}
+
+func TestPackedEmptySubImage(t *testing.T) {
+	empty := NewPacked(image.Rect(-5, -5, 10, 10)).SubImage(image.Rect(20, 20, 50, 50))
+	if empty.Bounds() != NewPacked(image.Rectangle{}).Bounds() {
+		t.Errorf("SubImage should produce an image with empty bounds when rects do not intersect")
+	}
+}
+
+func TestPackedPixelOperations(t *testing.T) {
+	bin := NewPacked(image.Rect(-3, 0, 10, 10))
+
+	blackRGBA := color.RGBA{0, 0, 0, 0xff}
+	whiteRGBA := color.RGBA{0xff, 0xff, 0xff, 0xff}
+
+	for _, x := range []int{-3, -1, 0, 7, 8, 9} {
+		y := 9
+		bin.Set(x, y, whiteRGBA)
+		if bit := bin.BitAt(x, y); bit != On {
+			t.Errorf("want bit at (%d,%d) to be On, got %v", x, y, bit)
+		}
+		bin.Set(x, y, blackRGBA)
+		if bit := bin.BitAt(x, y); bit != Off {
+			t.Errorf("want bit at (%d,%d) to be Off, got %v", x, y, bit)
+		}
+	}
+
+	// setting a bit that is out of the image bounds should not panic, nor do nothing
+	sub := bin.SubImage(image.Rect(1, 1, 2, 2)).(*Packed)
+	sub.SetBit(4, 4, On)
+
+	// getting a bit that is out of the image bound should return the zero
+	// value of the color type
+	var zero Bit
+	if bit := sub.BitAt(4, 4); bit != zero {
+		t.Errorf("expected BitAt to return Bit{} for out-of-bounds bit, got %v", bit)
+	}
+}
+
+func TestPackedSetRect(t *testing.T) {
+	var tests = []image.Rectangle{
+		image.Rect(0, 0, 1, 1),
+		image.Rect(2, 3, 6, 5),
+		image.Rect(-3, 0, 30, 2),
+		image.Rect(7, 1, 9, 8),
+		image.Rect(8, 8, 40, 40),
+		image.Rect(100, 100, 10, 10),
+	}
+
+	bounds := image.Rect(-5, -1, 27, 12)
+	for _, r := range tests {
+		// compare with the byte-per-pixel implementation
+		want, got := New(bounds), NewPacked(bounds)
+		want.SetRect(r, On)
+		got.SetRect(r, On)
+		if err := test.Diff(want, got); err != nil {
+			t.Errorf("SetRect(%v, On): %v", r, err)
+		}
+
+		want.SetRect(bounds, On)
+		got.SetRect(bounds, On)
+		want.SetRect(r, Off)
+		got.SetRect(r, Off)
+		if err := test.Diff(want, got); err != nil {
+			t.Errorf("SetRect(%v, Off): %v", r, err)
+		}
+	}
+}
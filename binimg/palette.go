@@ -0,0 +1,96 @@
+package binimg
+
+import "image/color"
+
+// BlackAndWhite is a palette rendering On and Off pixels as, respectively,
+// color.White and color.Black.
+var BlackAndWhite = &Palette{OnColor: color.White, OffColor: color.Black}
+
+// A Palette associates colors to the two logical values of a binary image.
+//
+// Palette implements color.Model, converting any color to either OnColor or
+// OffColor. A nil *Palette is valid, it converts colors with Model and
+// renders Bit values as themselves.
+type Palette struct {
+	// OnColor and OffColor are the colors of, respectively, On and Off pixels.
+	// If nil, they respectively default to color.White and color.Black, so
+	// that the zero Palette is equivalent to BlackAndWhite.
+	OnColor, OffColor color.Color
+
+	// Model converts to Bit the colors that are neither OnColor nor OffColor.
+	// If nil, the binimg Model is used.
+	Model color.Model
+}
+
+// Convert converts c to the palette color of the Bit c converts to.
+func (p *Palette) Convert(c color.Color) color.Color {
+	return p.Color(p.Bit(c))
+}
+
+// Color returns the color of Bit c.
+func (p *Palette) Color(c Bit) color.Color {
+	if p == nil {
+		return c
+	}
+	if c == Off {
+		return p.offColor()
+	}
+	return p.onColor()
+}
+
+// onColor returns the color of On pixels, color.White if OnColor is nil.
+func (p *Palette) onColor() color.Color {
+	if p.OnColor == nil {
+		return color.White
+	}
+	return p.OnColor
+}
+
+// offColor returns the color of Off pixels, color.Black if OffColor is nil.
+func (p *Palette) offColor() color.Color {
+	if p.OffColor == nil {
+		return color.Black
+	}
+	return p.OffColor
+}
+
+// Bit returns the Bit c converts to.
+//
+// Bit values are returned as-is, OnColor and OffColor are converted to,
+// respectively, On and Off. Any other color is converted with the palette
+// Model.
+func (p *Palette) Bit(c color.Color) Bit {
+	if bit, ok := c.(Bit); ok {
+		return bit
+	}
+	if p == nil {
+		return Model.Convert(c).(Bit)
+	}
+	switch {
+	case sameColor(c, p.onColor()):
+		return On
+	case sameColor(c, p.offColor()):
+		return Off
+	}
+	m := p.Model
+	if m == nil {
+		m = Model
+	}
+	return Model.Convert(m.Convert(c)).(Bit)
+}
+
+// isOpaque reports whether pixels of Bit c are rendered fully opaque.
+func (p *Palette) isOpaque(c Bit) bool {
+	if p == nil {
+		return true
+	}
+	_, _, _, a := p.Color(c).RGBA()
+	return a == 0xffff
+}
+
+// sameColor reports whether c0 and c1 have the same RGBA values.
+func sameColor(c0, c1 color.Color) bool {
+	r0, g0, b0, a0 := c0.RGBA()
+	r1, g1, b1, a1 := c1.RGBA()
+	return r0 == r1 && g0 == g1 && b0 == b1 && a0 == a1
+}
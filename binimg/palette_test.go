@@ -0,0 +1,147 @@
+package binimg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+var (
+	red  = color.RGBA{255, 0, 0, 255}
+	blue = color.RGBA{0, 0, 255, 255}
+)
+
+func TestPaletteFromImage(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/colorgopher.png")
+	test.Check(t, err)
+
+	refname := "../testdata/redblue.gopher.png"
+	ref, err := test.LoadPNG(refname)
+	test.Check(t, err)
+
+	p := &Palette{OnColor: blue, OffColor: red}
+	bin := NewWithPalette(src.Bounds(), p)
+	draw.Draw(bin, bin.Bounds(), src, image.Point{}, draw.Src)
+	if err := test.Diff(ref, bin); err != nil {
+		t.Errorf("converted image is different from %s: %v", refname, err)
+	}
+
+	// bits are not modified by the palette
+	if !bytes.Equal(bin.Pix, NewFromImage(src).Pix) {
+		t.Errorf("paletted image bits are different from the non-paletted ones")
+	}
+
+	packed := NewPacked(src.Bounds())
+	packed.Palette = p
+	draw.Draw(packed, packed.Bounds(), src, image.Point{}, draw.Src)
+	if err := test.Diff(ref, packed); err != nil {
+		t.Errorf("converted packed image is different from %s: %v", refname, err)
+	}
+}
+
+func TestPaletteConvert(t *testing.T) {
+	p := &Palette{OnColor: blue, OffColor: red}
+	high := &Palette{OnColor: blue, OffColor: red, Model: ThresholdModel(197, Rec601)}
+
+	var tests = []struct {
+		p    *Palette
+		col  color.Color
+		bit  Bit
+		want color.Color
+	}{
+		{p, On, On, blue},
+		{p, Off, Off, red},
+		{p, blue, On, blue},
+		{p, red, Off, red},
+		{p, color.NRGBA{0, 0, 255, 255}, On, blue},
+		{p, color.White, On, blue},
+		{p, color.Black, Off, red},
+		{p, color.Gray{150}, On, blue},
+		{high, color.Gray{150}, Off, red},
+		{BlackAndWhite, color.White, On, color.White},
+		{BlackAndWhite, color.Gray{150}, On, color.White},
+		{nil, color.Gray{150}, On, On},
+		{nil, color.Black, Off, Off},
+		// nil colors default to white and black
+		{&Palette{}, color.White, On, color.White},
+		{&Palette{}, color.Gray{50}, Off, color.Black},
+		{&Palette{OnColor: blue}, color.Black, Off, color.Black},
+		{&Palette{OnColor: blue}, blue, On, blue},
+		{&Palette{OffColor: red}, color.White, On, color.White},
+	}
+
+	for _, tt := range tests {
+		if bit := tt.p.Bit(tt.col); bit != tt.bit {
+			t.Errorf("Palette(%v).Bit(%v), want %v, got %v", tt.p, tt.col, tt.bit, bit)
+		}
+		if col := tt.p.Convert(tt.col); col != tt.want {
+			t.Errorf("Palette(%v).Convert(%v), want %v, got %v", tt.p, tt.col, tt.want, col)
+		}
+	}
+}
+
+func TestZeroPalette(t *testing.T) {
+	// a zero palette is usable as a color model and to render images
+	b := NewWithPalette(image.Rect(0, 0, 2, 1), &Palette{})
+	b.Set(0, 0, color.White)
+	b.Set(1, 0, color.RGBA{10, 10, 10, 255})
+	if got := b.At(0, 0); got != color.White {
+		t.Errorf("want On pixel rendered as white, got %v", got)
+	}
+	if got := b.At(1, 0); got != color.Black {
+		t.Errorf("want Off pixel rendered as black, got %v", got)
+	}
+	if !b.Opaque() {
+		t.Errorf("want image with zero palette opaque")
+	}
+}
+
+func TestPaletteImage(t *testing.T) {
+	p := &Palette{OnColor: blue, OffColor: red}
+	bin := NewWithPalette(image.Rect(0, 0, 10, 10), p)
+	if bin.ColorModel() != p {
+		t.Errorf("want color model to be the image palette")
+	}
+
+	bin.Set(2, 3, blue)
+	if bin.BitAt(2, 3) != On {
+		t.Errorf("want bit at (2,3) to be On, got %v", bin.BitAt(2, 3))
+	}
+	if bin.At(2, 3) != blue {
+		t.Errorf("want color at (2,3) to be %v, got %v", blue, bin.At(2, 3))
+	}
+	if bin.At(3, 3) != red {
+		t.Errorf("want color at (3,3) to be %v, got %v", red, bin.At(3, 3))
+	}
+
+	sub := bin.SubImage(image.Rect(2, 2, 4, 4)).(*Image)
+	if sub.Palette != p {
+		t.Errorf("want sub-image to share the image palette")
+	}
+}
+
+func TestPaletteOpaque(t *testing.T) {
+	transparent := &Palette{OnColor: blue, OffColor: color.Transparent}
+
+	bin := NewWithPalette(image.Rect(0, 0, 10, 10), transparent)
+	if bin.Opaque() {
+		t.Errorf("want image with transparent Off pixels not to be opaque")
+	}
+	bin.SetRect(bin.Bounds(), On)
+	if !bin.Opaque() {
+		t.Errorf("want image with only opaque On pixels to be opaque")
+	}
+
+	packed := Pack(bin)
+	if !packed.Opaque() {
+		t.Errorf("want packed image with only opaque On pixels to be opaque")
+	}
+	packed.SetBit(9, 9, Off)
+	if packed.Opaque() {
+		t.Errorf("want packed image with transparent Off pixels not to be opaque")
+	}
+}
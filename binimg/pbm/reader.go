@@ -0,0 +1,245 @@
+// Package pbm implements a Netpbm PBM (portable bitmap) image decoder and
+// encoder, decoding directly into binimg.Image.
+//
+// Both the plain (P1) and raw (P4) formats are supported. In PBM, 1 bits
+// represent black pixels, they are decoded as binimg.Off pixels.
+package pbm
+
+import (
+	"bufio"
+	"image"
+	"io"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+// A FormatError reports that the input is not a valid PBM.
+type FormatError string
+
+func (e FormatError) Error() string { return "pbm: invalid format: " + string(e) }
+
+const (
+	plainMagic = "P1"
+	rawMagic   = "P4"
+
+	// maxPixels is the maximum number of pixels of a decoded image.
+	maxPixels = 1 << 30
+)
+
+func init() {
+	image.RegisterFormat("pbm", plainMagic, Decode, DecodeConfig)
+	image.RegisterFormat("pbm", rawMagic, Decode, DecodeConfig)
+}
+
+type decoder struct {
+	r             *bufio.Reader
+	raw           bool
+	width, height int
+}
+
+// readHeader reads the magic number and the image dimensions, and the single
+// whitespace character that follows them.
+func (d *decoder) readHeader() error {
+	var magic [2]byte
+	if _, err := io.ReadFull(d.r, magic[:]); err != nil {
+		return unexpectedEOF(err)
+	}
+	switch string(magic[:]) {
+	case plainMagic:
+	case rawMagic:
+		d.raw = true
+	default:
+		return FormatError("bad magic number")
+	}
+
+	var err error
+	if d.width, err = d.readInt(); err != nil {
+		return err
+	}
+	if d.height, err = d.readInt(); err != nil {
+		return err
+	}
+	// compare without multiplying, that may overflow on 32-bit platforms
+	if d.height != 0 && d.width > maxPixels/d.height {
+		return FormatError("image too large")
+	}
+	// a single whitespace character separates the header from the raster
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	if !isSpace(c) {
+		return FormatError("missing whitespace after header")
+	}
+	return nil
+}
+
+// skipSpaces skips whitespaces and comments, that start with '#' and end at
+// the end of the line.
+func (d *decoder) skipSpaces() error {
+	for {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		switch {
+		case c == '#':
+			if err := d.skipLine(); err != nil {
+				return err
+			}
+		case !isSpace(c):
+			return d.r.UnreadByte()
+		}
+	}
+}
+
+// skipLine skips all the bytes up to, and including, the next newline.
+func (d *decoder) skipLine() error {
+	for {
+		_, err := d.r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		return unexpectedEOF(err)
+	}
+}
+
+// readInt reads a positive decimal integer, preceded by whitespaces or
+// comments.
+func (d *decoder) readInt() (int, error) {
+	if err := d.skipSpaces(); err != nil {
+		return 0, err
+	}
+	n, digits := 0, 0
+	for {
+		c, err := d.r.ReadByte()
+		if err == io.EOF && digits > 0 {
+			return n, nil
+		}
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		if c < '0' || c > '9' {
+			if digits == 0 {
+				return 0, FormatError("invalid integer")
+			}
+			return n, d.r.UnreadByte()
+		}
+		n = 10*n + int(c-'0')
+		digits++
+		if n > maxPixels {
+			return 0, FormatError("dimension too large")
+		}
+	}
+}
+
+// readPlain reads the plain raster, appending its pixels to pix.
+func (d *decoder) readPlain(pix []byte) ([]byte, error) {
+	for n := d.width * d.height; n > 0; n-- {
+		if err := d.skipSpaces(); err != nil {
+			return pix, err
+		}
+		c, _ := d.r.ReadByte()
+		switch c {
+		case '0':
+			pix = append(pix, binimg.On.V)
+		case '1':
+			pix = append(pix, binimg.Off.V)
+		default:
+			return pix, FormatError("invalid pixel value")
+		}
+	}
+	return pix, nil
+}
+
+// rawChunkSize is the number of bytes of a raw row read at once.
+const rawChunkSize = 512
+
+// readRaw reads the raw raster, appending its pixels to pix. Each row is
+// packed in bytes, the most significant bit first.
+func (d *decoder) readRaw(pix []byte) ([]byte, error) {
+	var chunk [rawChunkSize]byte
+	for y := 0; y < d.height; y++ {
+		for x := 0; x < d.width; {
+			n := (d.width - x + 7) / 8
+			if n > len(chunk) {
+				n = len(chunk)
+			}
+			if _, err := io.ReadFull(d.r, chunk[:n]); err != nil {
+				return pix, unexpectedEOF(err)
+			}
+			for _, v := range chunk[:n] {
+				for bit := uint(0); bit < 8 && x < d.width; bit++ {
+					if v&(0x80>>bit) == 0 {
+						pix = append(pix, binimg.On.V)
+					} else {
+						pix = append(pix, binimg.Off.V)
+					}
+					x++
+				}
+			}
+		}
+	}
+	return pix, nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Decode reads a PBM image from r and returns it as a *binimg.Image.
+func Decode(r io.Reader) (image.Image, error) {
+	img, err := DecodeBinary(r)
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// DecodeBinary reads a PBM image from r and returns it as a *binimg.Image.
+func DecodeBinary(r io.Reader) (*binimg.Image, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+	if err := d.readHeader(); err != nil {
+		return nil, err
+	}
+	// the pixel buffer grows as the raster is read, so that the size in
+	// the header of a truncated file doesn't cause a large allocation.
+	var (
+		pix []byte
+		err error
+	)
+	if d.raw {
+		pix, err = d.readRaw(pix)
+	} else {
+		pix, err = d.readPlain(pix)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &binimg.Image{
+		Pix:    pix,
+		Stride: d.width,
+		Rect:   image.Rect(0, 0, d.width, d.height),
+	}, nil
+}
+
+// DecodeConfig returns the color model and dimensions of a PBM image without
+// decoding the entire image.
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+	if err := d.readHeader(); err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{
+		ColorModel: binimg.Model,
+		Width:      d.width,
+		Height:     d.height,
+	}, nil
+}
@@ -0,0 +1,160 @@
+package pbm
+
+import (
+	"bytes"
+	"image"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/arl/imgtools/binimg"
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestDecodePlain(t *testing.T) {
+	const src = `P1
+# a 5x3 image
+5 3
+1 0 1 0 0
+01010 # comment at end of line
+1
+1
+1
+1
+1
+`
+	img, err := DecodeBinary(strings.NewReader(src))
+	test.Check(t, err)
+
+	want := []string{
+		"X.X..",
+		".X.X.",
+		"XXXXX",
+	}
+	if img.Rect != image.Rect(0, 0, 5, 3) {
+		t.Fatalf("want bounds %v, got %v", image.Rect(0, 0, 5, 3), img.Rect)
+	}
+	for y, row := range want {
+		for x, c := range row {
+			wantBit := binimg.On
+			if c == 'X' {
+				wantBit = binimg.Off
+			}
+			if got := img.BitAt(x, y); got != wantBit {
+				t.Errorf("pixel (%d,%d): want %v, got %v", x, y, wantBit, got)
+			}
+		}
+	}
+}
+
+func TestDecodeRaw(t *testing.T) {
+	// 10x2 image, rows are padded to 2 bytes
+	src := append([]byte("P4 10 2\n"), 0xc0, 0x40, 0x01, 0x80)
+	img, err := DecodeBinary(bytes.NewReader(src))
+	test.Check(t, err)
+
+	want := []string{
+		"XX.......X",
+		".......XX.",
+	}
+	for y, row := range want {
+		for x, c := range row {
+			wantBit := binimg.On
+			if c == 'X' {
+				wantBit = binimg.Off
+			}
+			if got := img.BitAt(x, y); got != wantBit {
+				t.Errorf("pixel (%d,%d): want %v, got %v", x, y, wantBit, got)
+			}
+		}
+	}
+}
+
+func TestDecodeRegistered(t *testing.T) {
+	src, err := test.LoadPNG("../../testdata/bwgopher.png")
+	test.Check(t, err)
+
+	for _, enc := range []struct {
+		name   string
+		encode func(io.Writer, image.Image) error
+	}{
+		{"plain", EncodePlain},
+		{"raw", Encode},
+	} {
+		var buf bytes.Buffer
+		test.Check(t, enc.encode(&buf, src))
+
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(buf.Bytes()))
+		test.Check(t, err)
+		if format != "pbm" {
+			t.Errorf("%s: want format pbm, got %q", enc.name, format)
+		}
+		if cfg.Width != 480 || cfg.Height != 480 || cfg.ColorModel != binimg.Model {
+			t.Errorf("%s: unexpected config %+v", enc.name, cfg)
+		}
+
+		img, format, err := image.Decode(&buf)
+		test.Check(t, err)
+		if format != "pbm" {
+			t.Errorf("%s: want format pbm, got %q", enc.name, format)
+		}
+		if _, ok := img.(*binimg.Image); !ok {
+			t.Errorf("%s: want *binimg.Image, got %T", enc.name, img)
+		}
+		if err := test.Diff(src, img); err != nil {
+			t.Errorf("%s: decoded image differs from original: %v", enc.name, err)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var tests = []struct {
+		name string
+		src  string
+		want error
+	}{
+		{"bad magic", "P2\n1 1\n1\n", FormatError("")},
+		{"missing width", "P1\n#\n", io.ErrUnexpectedEOF},
+		{"negative width", "P1 -1 1\n1\n", FormatError("")},
+		{"too large", "P4 1073741824 1073741824\n", FormatError("")},
+		{"too large product", "P4 65536 16385\n", FormatError("")},
+		{"bad pixel", "P1 2 1\n1 2\n", FormatError("")},
+		{"truncated plain", "P1 2 2\n1 0 1\n", io.ErrUnexpectedEOF},
+		{"truncated raw", "P4 9 2\n\x00\x00\x00", io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		_, err := Decode(strings.NewReader(tt.src))
+		if _, ok := tt.want.(FormatError); ok {
+			if _, ok := err.(FormatError); !ok {
+				t.Errorf("%s: want FormatError, got %v", tt.name, err)
+			}
+			continue
+		}
+		if err != tt.want {
+			t.Errorf("%s: want %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestDecodeTruncatedLarge(t *testing.T) {
+	// the headers announce 1 GiB images, the truncated rasters must be
+	// reported before allocating them
+	for _, src := range []string{
+		"P4 32768 32768\n\x00\x00",
+		"P4 1073741824 1\n\x00",
+		"P1 32768 32768\n0 1 0",
+	} {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err := Decode(strings.NewReader(src))
+		runtime.ReadMemStats(&after)
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("%q: want %v, got %v", src, io.ErrUnexpectedEOF, err)
+		}
+		if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+			t.Errorf("%q: want less than 1 MiB allocated, got %d bytes", src, n)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package pbm
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+// maxLineLength is the maximum length of the lines of a plain PBM raster.
+const maxLineLength = 70
+
+// Encode writes the image m to w in raw PBM (P4) format.
+//
+// Pixels of m are converted to binimg.Bit with binimg.Model, unless m is a
+// *binimg.Image, in which case its bits are written as-is, regardless of its
+// palette.
+func Encode(w io.Writer, m image.Image) error {
+	return encode(w, m, true)
+}
+
+// EncodePlain writes the image m to w in plain PBM (P1) format.
+//
+// Pixels are converted like in Encode.
+func EncodePlain(w io.Writer, m image.Image) error {
+	return encode(w, m, false)
+}
+
+func encode(w io.Writer, m image.Image, raw bool) error {
+	b := m.Bounds()
+	bw := bufio.NewWriter(w)
+	magic := plainMagic
+	if raw {
+		magic = rawMagic
+	}
+	if _, err := fmt.Fprintf(bw, "%s\n%d %d\n", magic, b.Dx(), b.Dy()); err != nil {
+		return err
+	}
+
+	bin, ok := m.(*binimg.Image)
+	if !ok {
+		bin = binimg.NewFromImageWithModel(m, binimg.Model)
+	}
+
+	var buf []byte
+	if raw {
+		buf = make([]byte, (b.Dx()+7)/8)
+	} else {
+		buf = make([]byte, 0, maxLineLength+1)
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := bin.PixOffset(b.Min.X, y)
+		row := bin.Pix[i : i+b.Dx()]
+		if raw {
+			for j := range buf {
+				buf[j] = 0
+			}
+			for x, v := range row {
+				if v == binimg.Off.V {
+					buf[x/8] |= 0x80 >> uint(x%8)
+				}
+			}
+			if _, err := bw.Write(buf); err != nil {
+				return err
+			}
+			continue
+		}
+		for x, v := range row {
+			c := byte('0')
+			if v == binimg.Off.V {
+				c = '1'
+			}
+			buf = append(buf, c)
+			if len(buf) == maxLineLength || x == len(row)-1 {
+				buf = append(buf, '\n')
+				if _, err := bw.Write(buf); err != nil {
+					return err
+				}
+				buf = buf[:0]
+			}
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,84 @@
+package pbm
+
+import (
+	"bufio"
+	"bytes"
+	"image"
+	"testing"
+
+	"github.com/arl/imgtools/binimg"
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestEncodeRoundTrip(t *testing.T) {
+	src, err := test.LoadPNG("../../testdata/colorgopher.png")
+	test.Check(t, err)
+	ref, err := test.LoadPNG("../../testdata/bwgopher.png")
+	test.Check(t, err)
+
+	bin := binimg.NewFromImage(src)
+	var tests = []image.Image{
+		src,
+		bin,
+		bin.SubImage(image.Rect(3, 5, 220, 301)),
+		binimg.NewFromImageWithModel(src, binimg.ThresholdModel(37, binimg.Rec601)),
+	}
+
+	for i, m := range tests {
+		var buf bytes.Buffer
+		test.Check(t, Encode(&buf, m))
+		raw, err := DecodeBinary(&buf)
+		test.Check(t, err)
+
+		buf.Reset()
+		test.Check(t, EncodePlain(&buf, m))
+		plain, err := DecodeBinary(&buf)
+		test.Check(t, err)
+
+		want := m
+		if i == 0 {
+			want = ref
+		}
+		if raw.Rect.Size() != want.Bounds().Size() {
+			t.Fatalf("test %d: want size %v, got %v", i, want.Bounds().Size(), raw.Rect.Size())
+		}
+		// decoded images have their origin at (0, 0)
+		min := want.Bounds().Min
+		for y := 0; y < raw.Rect.Dy(); y++ {
+			for x := 0; x < raw.Rect.Dx(); x++ {
+				wantBit := binimg.Model.Convert(want.At(min.X+x, min.Y+y))
+				if raw.BitAt(x, y) != wantBit || plain.BitAt(x, y) != wantBit {
+					t.Fatalf("test %d: pixel (%d,%d): want %v, got raw %v, plain %v",
+						i, x, y, wantBit, raw.BitAt(x, y), plain.BitAt(x, y))
+				}
+			}
+		}
+	}
+}
+
+func TestEncodePlainLineLength(t *testing.T) {
+	img := binimg.New(image.Rect(0, 0, 150, 2))
+	var buf bytes.Buffer
+	test.Check(t, EncodePlain(&buf, img))
+
+	s := bufio.NewScanner(&buf)
+	for s.Scan() {
+		if len(s.Text()) > maxLineLength {
+			t.Errorf("want lines of at most %d characters, got %d", maxLineLength, len(s.Text()))
+		}
+	}
+}
+
+func TestEncodeRaw(t *testing.T) {
+	img := binimg.New(image.Rect(0, 0, 10, 2))
+	img.SetRect(img.Rect, binimg.On)
+	img.SetBit(0, 0, binimg.Off)
+	img.SetBit(9, 1, binimg.Off)
+
+	var buf bytes.Buffer
+	test.Check(t, Encode(&buf, img))
+	want := append([]byte("P4\n10 2\n"), 0x80, 0x00, 0x00, 0x40)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("want %q, got %q", want, buf.Bytes())
+	}
+}
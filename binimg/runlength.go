@@ -0,0 +1,393 @@
+package binimg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"sort"
+)
+
+// A Run is a horizontal run of On pixels, from X0 included to X1 excluded.
+type Run struct {
+	X0, X1 int
+}
+
+// RunLength is an in-memory, run-length encoded, binary image whose At method
+// returns Bit values.
+//
+// Contrary to Image, RunLength only stores the runs of On pixels of each row,
+// making it compact for sparse images, i.e mostly made of Off pixels, and
+// making region queries proportional to the number of runs instead of the
+// number of pixels.
+type RunLength struct {
+	// Rows holds the runs of On pixels of the image rows, from top to bottom.
+	// The runs of a row are sorted by x, they are neither empty, overlapping
+	// nor adjacent, and they are contained in Rect.
+	Rows [][]Run
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+	// Palette is the image's palette. If nil, On and Off pixels are
+	// respectively rendered as White and Black.
+	Palette *Palette
+}
+
+// ColorModel returns the image.Image's color model.
+func (rl *RunLength) ColorModel() color.Model {
+	if rl.Palette != nil {
+		return rl.Palette
+	}
+	return Model
+}
+
+// Bounds returns the domain for which At can return non-zero color.
+// The bounds do not necessarily contain the point (0, 0).
+func (rl *RunLength) Bounds() image.Rectangle { return rl.Rect }
+
+// At returns the color of the pixel at (x, y).
+// At(Bounds().Min.X, Bounds().Min.Y) returns the upper-left pixel of the grid.
+// At(Bounds().Max.X-1, Bounds().Max.Y-1) returns the lower-right one.
+func (rl *RunLength) At(x, y int) color.Color {
+	return rl.Palette.Color(rl.BitAt(x, y))
+}
+
+// BitAt returns the Bit color of the pixel at (x, y).
+// BitAt(Bounds().Min.X, Bounds().Min.Y) returns the upper-left pixel of the
+// grid. BitAt(Bounds().Max.X-1, Bounds().Max.Y-1) returns the lower-right
+// one.
+func (rl *RunLength) BitAt(x, y int) Bit {
+	if !(image.Point{x, y}.In(rl.Rect)) {
+		return Bit{}
+	}
+	row := rl.Rows[y-rl.Rect.Min.Y]
+	// first run ending after x
+	i := sort.Search(len(row), func(i int) bool { return row[i].X1 > x })
+	if i < len(row) && row[i].X0 <= x {
+		return On
+	}
+	return Off
+}
+
+// Set sets the color of the pixel at (x, y).
+//
+// c is converted to Bit using the image palette.
+func (rl *RunLength) Set(x, y int, c color.Color) {
+	rl.SetBit(x, y, rl.Palette.Bit(c))
+}
+
+// SetBit sets the Bit of the pixel at (x, y).
+func (rl *RunLength) SetBit(x, y int, c Bit) {
+	rl.SetRect(image.Rect(x, y, x+1, y+1), c)
+}
+
+// SetRect sets all the pixels in the rectangle defined by given rectangle.
+func (rl *RunLength) SetRect(r image.Rectangle, c Bit) {
+	r = r.Intersect(rl.Rect)
+	if r.Empty() {
+		return
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := y - rl.Rect.Min.Y
+		rl.Rows[i] = setSpan(rl.Rows[i], r.Min.X, r.Max.X, c)
+	}
+}
+
+// Opaque scans the entire image and reports whether it is fully opaque.
+func (rl *RunLength) Opaque() bool {
+	on, off := rl.Palette.isOpaque(On), rl.Palette.isOpaque(Off)
+	if on && off {
+		return true
+	}
+	if rl.Rect.Empty() {
+		return true
+	}
+	// the image is opaque if it's only made of pixels of the opaque bit
+	if on {
+		uniform, bit := rl.IsUniform(rl.Rect)
+		return uniform && bit == On
+	}
+	if off {
+		return rl.Area(rl.Rect) == 0
+	}
+	return false
+}
+
+// Area returns the number of On pixels in the region r.
+func (rl *RunLength) Area(r image.Rectangle) int {
+	r = r.Intersect(rl.Rect)
+	area := 0
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := rl.Rows[y-rl.Rect.Min.Y]
+		for i := firstRun(row, r.Min.X); i < len(row) && row[i].X0 < r.Max.X; i++ {
+			area += minInt(row[i].X1, r.Max.X) - maxInt(row[i].X0, r.Min.X)
+		}
+	}
+	return area
+}
+
+// IsUniform reports whether all the pixels in the region r have the same
+// Bit, and returns it. An empty region is uniformly Off.
+func (rl *RunLength) IsUniform(r image.Rectangle) (bool, Bit) {
+	r = r.Intersect(rl.Rect)
+	if r.Empty() {
+		return true, Off
+	}
+	var bit Bit
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := rl.Rows[y-rl.Rect.Min.Y]
+		i := firstRun(row, r.Min.X)
+		// the row is either fully covered by a single run, or not touched
+		// by any
+		b := Off
+		if i < len(row) && row[i].X0 < r.Max.X {
+			if row[i].X0 > r.Min.X || row[i].X1 < r.Max.X {
+				return false, Bit{}
+			}
+			b = On
+		}
+		if y == r.Min.Y {
+			bit = b
+		} else if b != bit {
+			return false, Bit{}
+		}
+	}
+	return true, bit
+}
+
+// firstRun returns the index of the first run of row ending after x.
+func firstRun(row []Run, x int) int {
+	return sort.Search(len(row), func(i int) bool { return row[i].X1 > x })
+}
+
+// setSpan sets the pixels of row from x0 included to x1 excluded to c, and
+// returns the modified row.
+func setSpan(row []Run, x0, x1 int, c Bit) []Run {
+	// runs from i to j excluded overlap the span, or are adjacent to it
+	i := sort.Search(len(row), func(i int) bool { return row[i].X1 >= x0 })
+	j := sort.Search(len(row), func(i int) bool { return row[i].X0 > x1 })
+
+	var repl [2]Run
+	n := 0
+	if c == On {
+		if i < j {
+			x0, x1 = minInt(x0, row[i].X0), maxInt(x1, row[j-1].X1)
+		}
+		repl[0] = Run{x0, x1}
+		n = 1
+	} else {
+		if i < j && row[i].X0 < x0 {
+			repl[n] = Run{row[i].X0, x0}
+			n++
+		}
+		if i < j && row[j-1].X1 > x1 {
+			repl[n] = Run{x1, row[j-1].X1}
+			n++
+		}
+	}
+
+	// replace row[i:j] by repl[:n]
+	switch d := n - (j - i); {
+	case d > 0:
+		row = append(row, repl[:d]...)
+		copy(row[j+d:], row[j:len(row)-d])
+	case d < 0:
+		copy(row[j+d:], row[j:])
+		row = row[:len(row)+d]
+	}
+	copy(row[i:], repl[:n])
+	return row
+}
+
+// RunLengthEncode returns a new run-length encoded image holding the same
+// pixels, and sharing the palette, of b.
+func RunLengthEncode(b *Image) *RunLength {
+	rl := NewRunLength(b.Rect)
+	rl.Palette = b.Palette
+	for y := b.Rect.Min.Y; y < b.Rect.Max.Y; y++ {
+		i := b.PixOffset(b.Rect.Min.X, y)
+		pix := b.Pix[i : i+b.Rect.Dx()]
+		var row []Run
+		for x := 0; x < len(pix); {
+			start := bytes.IndexByte(pix[x:], On.V)
+			if start == -1 {
+				break
+			}
+			start += x
+			end := bytes.IndexByte(pix[start:], Off.V)
+			if end == -1 {
+				end = len(pix)
+			} else {
+				end += start
+			}
+			row = append(row, Run{b.Rect.Min.X + start, b.Rect.Min.X + end})
+			x = end
+		}
+		rl.Rows[y-b.Rect.Min.Y] = row
+	}
+	return rl
+}
+
+// RunLengthDecode returns a new byte-per-pixel binary image holding the same
+// pixels, and sharing the palette, of rl.
+func RunLengthDecode(rl *RunLength) *Image {
+	b := NewWithPalette(rl.Rect, rl.Palette)
+	for y, row := range rl.Rows {
+		for _, run := range row {
+			i := b.PixOffset(run.X0, rl.Rect.Min.Y+y)
+			fillRow(b.Pix[i:i+run.X1-run.X0], On.V)
+		}
+	}
+	return b
+}
+
+// NewRunLength returns a new run-length encoded binary image with the given
+// bounds, made of Off pixels.
+func NewRunLength(r image.Rectangle) *RunLength {
+	return &RunLength{
+		Rows: make([][]Run, maxInt(r.Dy(), 0)),
+		Rect: r,
+	}
+}
+
+// NewRunLengthFromImage returns a new run-length encoded binary image that is
+// the conversion of src image.
+func NewRunLengthFromImage(src image.Image) *RunLength {
+	b, ok := src.(*Image)
+	if !ok {
+		b = NewFromImage(src)
+	}
+	return RunLengthEncode(b)
+}
+
+// CombineRuns returns a new run-length encoded image whose pixels are the
+// combination with op of the pixels of a and b. Its bounds are the
+// intersection of the bounds of a and b, and its palette is the palette of a.
+func CombineRuns(a, b *RunLength, op BoolOp) *RunLength {
+	r := a.Rect.Intersect(b.Rect)
+	dst := NewRunLength(r)
+	dst.Palette = a.Palette
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := combineRuns(nil, a.Rows[y-a.Rect.Min.Y], b.Rows[y-b.Rect.Min.Y], op)
+		dst.Rows[y-r.Min.Y] = clipRuns(row, r.Min.X, r.Max.X)
+	}
+	return dst
+}
+
+// NotRuns returns a new run-length encoded image, with the same bounds and
+// palette as a, whose pixels are the inverse of the pixels of a.
+func NotRuns(a *RunLength) *RunLength {
+	dst := NewRunLength(a.Rect)
+	dst.Palette = a.Palette
+	for y, row := range a.Rows {
+		var inv []Run
+		x := a.Rect.Min.X
+		for _, run := range row {
+			if run.X0 > x {
+				inv = append(inv, Run{x, run.X0})
+			}
+			x = run.X1
+		}
+		if x < a.Rect.Max.X {
+			inv = append(inv, Run{x, a.Rect.Max.X})
+		}
+		dst.Rows[y] = inv
+	}
+	return dst
+}
+
+// combineRuns appends to dst the runs of the combination with op of the runs
+// a and b, and returns the extended slice.
+func combineRuns(dst, a, b []Run, op BoolOp) []Run {
+	// sweep the boundaries of the runs of a and b from left to right,
+	// keeping track of whether we're inside a run of a, b, and dst.
+	var ina, inb, in bool
+	var start int
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		x := int(^uint(0) >> 1)
+		if i < len(a) {
+			x = a[i].X0
+			if ina {
+				x = a[i].X1
+			}
+		}
+		if j < len(b) {
+			xb := b[j].X0
+			if inb {
+				xb = b[j].X1
+			}
+			x = minInt(x, xb)
+		}
+
+		if i < len(a) && x == a[i].X0 && !ina {
+			ina = true
+		} else if i < len(a) && x == a[i].X1 && ina {
+			ina = false
+			i++
+		}
+		if j < len(b) && x == b[j].X0 && !inb {
+			inb = true
+		} else if j < len(b) && x == b[j].X1 && inb {
+			inb = false
+			j++
+		}
+
+		on := false
+		switch op {
+		case And:
+			on = ina && inb
+		case Or:
+			on = ina || inb
+		case Xor:
+			on = ina != inb
+		case AndNot:
+			on = ina && !inb
+		}
+		switch {
+		case on && !in:
+			start = x
+			// merge with the previous run if adjacent
+			if n := len(dst); n > 0 && dst[n-1].X1 == x {
+				start = dst[n-1].X0
+				dst = dst[:n-1]
+			}
+		case !on && in:
+			dst = append(dst, Run{start, x})
+		}
+		in = on
+	}
+	return dst
+}
+
+// clipRuns clips the runs of row to [x0, x1), in place.
+func clipRuns(row []Run, x0, x1 int) []Run {
+	n := 0
+	for _, run := range row {
+		run.X0, run.X1 = maxInt(run.X0, x0), minInt(run.X1, x1)
+		if run.X0 < run.X1 {
+			row[n] = run
+			n++
+		}
+	}
+	return row[:n]
+}
+
+// fillRow sets all the elements of row to v.
+func fillRow(row []uint8, v uint8) {
+	for i := range row {
+		row[i] = v
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,197 @@
+package binimg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+// checkRuns checks that the runs of rl are sorted, non-empty, neither
+// overlapping nor adjacent, and contained in its bounds.
+func checkRuns(t *testing.T, rl *RunLength) {
+	t.Helper()
+	if len(rl.Rows) != rl.Rect.Dy() {
+		t.Fatalf("want %d rows, got %d", rl.Rect.Dy(), len(rl.Rows))
+	}
+	for y, row := range rl.Rows {
+		x := rl.Rect.Min.X - 1
+		for _, run := range row {
+			if run.X0 <= x || run.X0 >= run.X1 || run.X1 > rl.Rect.Max.X {
+				t.Fatalf("row %d: invalid runs %v", y, row)
+			}
+			x = run.X1
+		}
+	}
+}
+
+func TestRunLengthEncodeDecode(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/colorgopher.png")
+	test.Check(t, err)
+
+	var tests = []image.Rectangle{
+		image.Rect(0, 0, 480, 480),
+		image.Rect(3, 5, 17, 23),
+		image.Rect(352, 352, 480, 480),
+		image.Rect(9, 1, 10, 2),
+	}
+
+	bin := NewFromImage(src)
+	for _, r := range tests {
+		sub := bin.SubImage(r).(*Image)
+		rl := RunLengthEncode(sub)
+		checkRuns(t, rl)
+		if err := test.Diff(sub, rl); err != nil {
+			t.Errorf("RunLengthEncode(%v) differs from original: %v", r, err)
+		}
+		dec := RunLengthDecode(rl)
+		if dec.Rect != sub.Rect {
+			t.Errorf("want RunLengthDecode bounds %v, got %v", sub.Rect, dec.Rect)
+		}
+		if err := test.Diff(sub, dec); err != nil {
+			t.Errorf("RunLengthDecode(RunLengthEncode(%v)) differs from original: %v", r, err)
+		}
+	}
+
+	rl := NewRunLengthFromImage(src)
+	refname := "../testdata/bwgopher.png"
+	ref, err := test.LoadPNG(refname)
+	test.Check(t, err)
+	if err := test.Diff(ref, rl); err != nil {
+		t.Errorf("converted image is different from %s: %v", refname, err)
+	}
+}
+
+func TestRunLengthSetRect(t *testing.T) {
+	var tests = []image.Rectangle{
+		image.Rect(0, 0, 1, 1),
+		image.Rect(2, 3, 6, 5),
+		image.Rect(-3, 0, 30, 2),
+		image.Rect(7, 1, 9, 8),
+		image.Rect(8, 8, 40, 40),
+		image.Rect(100, 100, 10, 10),
+	}
+
+	bounds := image.Rect(-5, -1, 27, 12)
+	want, got := New(bounds), NewRunLength(bounds)
+	for _, r := range tests {
+		// compare with the byte-per-pixel implementation
+		want.SetRect(r, On)
+		got.SetRect(r, On)
+		checkRuns(t, got)
+		if err := test.Diff(want, got); err != nil {
+			t.Errorf("SetRect(%v, On): %v", r, err)
+		}
+	}
+	for _, r := range tests {
+		want.SetRect(r.Add(image.Pt(1, 0)), Off)
+		got.SetRect(r.Add(image.Pt(1, 0)), Off)
+		checkRuns(t, got)
+		if err := test.Diff(want, got); err != nil {
+			t.Errorf("SetRect(%v, Off): %v", r, err)
+		}
+	}
+
+	// random pixels, merging and splitting runs
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		x, y := bounds.Min.X+rnd.Intn(bounds.Dx()), bounds.Min.Y+rnd.Intn(bounds.Dy())
+		c := Bit{V: uint8(rnd.Intn(2) * 0xff)}
+		want.SetBit(x, y, c)
+		got.Set(x, y, c)
+	}
+	checkRuns(t, got)
+	if err := test.Diff(want, got); err != nil {
+		t.Errorf("SetBit: %v", err)
+	}
+}
+
+func TestRunLengthQueries(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/bwgopher.png")
+	test.Check(t, err)
+	bin := NewFromImage(src)
+	rl := RunLengthEncode(bin)
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		x, y := rnd.Intn(500)-10, rnd.Intn(500)-10
+		r := image.Rect(x, y, x+rnd.Intn(40), y+rnd.Intn(40))
+
+		// compare with a pixel by pixel scan
+		area, bits := 0, map[Bit]bool{}
+		ri := r.Intersect(bin.Rect)
+		for y := ri.Min.Y; y < ri.Max.Y; y++ {
+			for x := ri.Min.X; x < ri.Max.X; x++ {
+				bit := bin.BitAt(x, y)
+				bits[bit] = true
+				if bit == On {
+					area++
+				}
+			}
+		}
+		if got := rl.Area(r); got != area {
+			t.Fatalf("Area(%v): want %d, got %d", r, area, got)
+		}
+		uniform, bit := rl.IsUniform(r)
+		if uniform != (len(bits) <= 1) {
+			t.Fatalf("IsUniform(%v): want %v, got %v", r, len(bits) <= 1, uniform)
+		}
+		if uniform && len(bits) == 1 && !bits[bit] {
+			t.Fatalf("IsUniform(%v): want %v, got %v", r, bits, bit)
+		}
+	}
+}
+
+func TestCombineRuns(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/bwgopher.png")
+	test.Check(t, err)
+	bin := NewFromImage(src)
+
+	a := bin.SubImage(image.Rect(0, 0, 300, 300)).(*Image)
+	b := Not(bin).SubImage(image.Rect(100, 50, 480, 400)).(*Image)
+	ra, rb := RunLengthEncode(a), RunLengthEncode(b)
+
+	for _, op := range []BoolOp{And, Or, Xor, AndNot} {
+		got := CombineRuns(ra, rb, op)
+		checkRuns(t, got)
+		want := Combine(a, b, op)
+		if got.Rect != want.Rect {
+			t.Errorf("op %d: want bounds %v, got %v", op, want.Rect, got.Rect)
+		}
+		if err := test.Diff(want, got); err != nil {
+			t.Errorf("op %d: %v", op, err)
+		}
+	}
+
+	got := NotRuns(ra)
+	checkRuns(t, got)
+	if err := test.Diff(Not(a), got); err != nil {
+		t.Errorf("NotRuns: %v", err)
+	}
+}
+
+func TestRunLengthDraw(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/bwgopher.png")
+	test.Check(t, err)
+	bin := NewFromImage(src)
+	bin.Palette = &Palette{OnColor: color.RGBA{0, 0, 255, 255}, OffColor: color.RGBA{255, 0, 0, 255}}
+	rl := RunLengthEncode(bin)
+	if rl.ColorModel() != bin.Palette {
+		t.Errorf("want run-length image to share the palette")
+	}
+
+	// draw run-length image onto a RGBA image
+	want := image.NewRGBA(bin.Rect)
+	draw.Draw(want, want.Rect, bin, image.Point{}, draw.Src)
+	got := image.NewRGBA(bin.Rect)
+	draw.Draw(got, got.Rect, rl, image.Point{}, draw.Src)
+	if err := test.Diff(want, got); err != nil {
+		t.Errorf("drawn run-length image differs: %v", err)
+	}
+	if !rl.Opaque() {
+		t.Errorf("want opaque image")
+	}
+}
@@ -0,0 +1,273 @@
+package binimg
+
+import (
+	"image"
+	"math/bits"
+)
+
+// A ThinningAlgorithm is an algorithm reducing the shapes of a binary image
+// to their skeleton.
+type ThinningAlgorithm int
+
+// Thinning algorithms.
+const (
+	// ZhangSuen is the parallel thinning algorithm of Zhang and Suen.
+	ZhangSuen ThinningAlgorithm = iota
+	// GuoHall is the parallel thinning algorithm of Guo and Hall, that
+	// produces thinner diagonal strokes than ZhangSuen.
+	GuoHall
+)
+
+// The 8 neighbours of a pixel are represented as the bits of a mask, in
+// clockwise order (the y axis pointing down), starting from the north
+// neighbour at bit 0. In the literature, they're named P2 to P9.
+const (
+	p2 = 1 << iota // north
+	p3             // north-east
+	p4             // east
+	p5             // south-east
+	p6             // south
+	p7             // south-west
+	p8             // west
+	p9             // north-west
+)
+
+// deletable holds, for each thinning algorithm and sub-iteration, whether a
+// pixel is deleted given its neighbour mask.
+var deletable [2][2][256]bool
+
+func init() {
+	for m := 0; m < 256; m++ {
+		n := uint8(m)
+		deletable[ZhangSuen][0][m], deletable[ZhangSuen][1][m] = zhangSuen(n)
+		deletable[GuoHall][0][m], deletable[GuoHall][1][m] = guoHall(n)
+	}
+}
+
+// zhangSuen reports whether a pixel with neighbour mask n is deleted in the
+// first and second sub-iterations of the Zhang-Suen algorithm.
+func zhangSuen(n uint8) (first, second bool) {
+	on := func(p uint8) bool { return n&p != 0 }
+	count := bits.OnesCount8(n)
+	if count < 2 || count > 6 || crossings(n) != 1 {
+		return false, false
+	}
+	first = !(on(p2) && on(p4) && on(p6)) && !(on(p4) && on(p6) && on(p8))
+	second = !(on(p2) && on(p4) && on(p8)) && !(on(p2) && on(p6) && on(p8))
+	return first, second
+}
+
+// guoHall reports whether a pixel with neighbour mask n is deleted in the
+// first and second sub-iterations of the Guo-Hall algorithm.
+func guoHall(n uint8) (first, second bool) {
+	b := func(p uint8) int {
+		if n&p != 0 {
+			return 1
+		}
+		return 0
+	}
+	// number of distinct 8-connected components of the neighbours
+	c := (1-b(p2))&(b(p3)|b(p4)) + (1-b(p4))&(b(p5)|b(p6)) +
+		(1-b(p6))&(b(p7)|b(p8)) + (1-b(p8))&(b(p9)|b(p2))
+	n1 := (b(p9) | b(p2)) + (b(p3) | b(p4)) + (b(p5) | b(p6)) + (b(p7) | b(p8))
+	n2 := (b(p2) | b(p3)) + (b(p4) | b(p5)) + (b(p6) | b(p7)) + (b(p8) | b(p9))
+	if n2 < n1 {
+		n1 = n2
+	}
+	if c != 1 || n1 < 2 || n1 > 3 {
+		return false, false
+	}
+	first = (b(p6)|b(p7)|(1-b(p9)))&b(p8) == 0
+	second = (b(p2)|b(p3)|(1-b(p5)))&b(p4) == 0
+	return first, second
+}
+
+// crossings returns the number of Off to On transitions in the circular
+// sequence of neighbours of mask n.
+func crossings(n uint8) int {
+	rotated := n>>1 | n<<7
+	return bits.OnesCount8(^n & rotated)
+}
+
+// thinFrame holds the pixels of a binary image in a frame of Off pixels, 1
+// for On pixels and 0 for Off pixels, so that neighbours are always
+// addressable.
+type thinFrame struct {
+	pix    []uint8
+	stride int
+	ring   [8]int // index offsets of the neighbours, in neighbour mask order
+}
+
+func newThinFrame(b *Image) *thinFrame {
+	w, h := b.Rect.Dx(), b.Rect.Dy()
+	pw := w + 2
+	f := &thinFrame{pix: make([]uint8, pw*(h+2)), stride: pw}
+	f.ring = [8]int{-pw, -pw + 1, 1, pw + 1, pw, pw - 1, -1, -pw - 1}
+	for y := 0; y < h; y++ {
+		i := b.PixOffset(b.Rect.Min.X, b.Rect.Min.Y+y)
+		for x, v := range b.Pix[i : i+w] {
+			if v != Off.V {
+				f.pix[(y+1)*pw+x+1] = 1
+			}
+		}
+	}
+	return f
+}
+
+// mask returns the neighbour mask of the pixel at index i.
+func (f *thinFrame) mask(i int) uint8 {
+	var n uint8
+	for k, off := range f.ring {
+		n |= f.pix[i+off] << uint(k)
+	}
+	return n
+}
+
+// point returns the coordinates in b of the pixel at index i.
+func (f *thinFrame) point(b *Image, i int) image.Point {
+	return image.Pt(b.Rect.Min.X+i%f.stride-1, b.Rect.Min.Y+i/f.stride-1)
+}
+
+// image returns a new image, with the bounds and palette of b, of the
+// pixels of f.
+func (f *thinFrame) image(b *Image) *Image {
+	dst := NewWithPalette(b.Rect, b.Palette)
+	w := b.Rect.Dx()
+	for y := 0; y < b.Rect.Dy(); y++ {
+		i := dst.PixOffset(b.Rect.Min.X, b.Rect.Min.Y+y)
+		for x, v := range f.pix[(y+1)*f.stride+1 : (y+1)*f.stride+1+w] {
+			if v != 0 {
+				dst.Pix[i+x] = On.V
+			}
+		}
+	}
+	return dst
+}
+
+// Thin returns a new image, with the same bounds and palette as b, whose On
+// pixels are the skeleton of the On pixels of b, obtained with the thinning
+// algorithm alg. The skeleton is one pixel wide and preserves the
+// 8-connectivity of the shapes. Pixels out of the bounds of b are Off.
+func Thin(b *Image, alg ThinningAlgorithm) *Image {
+	f := newThinFrame(b)
+	var del []int
+	for changed := true; changed; {
+		changed = false
+		for iter := 0; iter < 2; iter++ {
+			// pixels of a sub-iteration are deleted in parallel
+			del = del[:0]
+			for i, v := range f.pix {
+				if v != 0 && deletable[alg][iter][f.mask(i)] {
+					del = append(del, i)
+				}
+			}
+			for _, i := range del {
+				f.pix[i] = 0
+			}
+			changed = changed || len(del) != 0
+		}
+	}
+	return f.image(b)
+}
+
+// isEndpoint reports whether a skeleton pixel with neighbour mask n is an
+// endpoint, i.e has a single neighbour, or two adjacent ones.
+func isEndpoint(n uint8) bool {
+	count := bits.OnesCount8(n)
+	return count == 1 || count == 2 && crossings(n) == 1
+}
+
+// isBranchPoint reports whether a skeleton pixel with neighbour mask n is a
+// branch point, i.e its neighbours form at least 3 distinct branches.
+func isBranchPoint(n uint8) bool {
+	return crossings(n) >= 3
+}
+
+// Endpoints returns the endpoints of the skeleton made of the On pixels of
+// b, in raster order. An endpoint is an On pixel having a single On
+// neighbour, or two adjacent ones.
+func Endpoints(b *Image) []image.Point {
+	return skeletonPoints(b, isEndpoint)
+}
+
+// BranchPoints returns the branch points of the skeleton made of the On
+// pixels of b, in raster order. A branch point is an On pixel whose On
+// neighbours form at least 3 branches, i.e there are at least 3 Off to On
+// transitions when going around it.
+func BranchPoints(b *Image) []image.Point {
+	return skeletonPoints(b, isBranchPoint)
+}
+
+// skeletonPoints returns, in raster order, the On pixels of b whose
+// neighbour mask satisfies match.
+func skeletonPoints(b *Image, match func(uint8) bool) []image.Point {
+	f := newThinFrame(b)
+	var pts []image.Point
+	for i, v := range f.pix {
+		if v != 0 && match(f.mask(i)) {
+			pts = append(pts, f.point(b, i))
+		}
+	}
+	return pts
+}
+
+// Prune returns a new image, with the same bounds and palette as b, in which
+// the spurs of the skeleton made of the On pixels of b, that are shorter than
+// n pixels, have been removed.
+//
+// A spur is a path of pixels going from an endpoint to a branch point, the
+// branch point not being part of the spur. Spurs are all found on the
+// original skeleton, then removed together. Isolated paths, that are not
+// connected to any branch point, are left untouched.
+func Prune(b *Image, n int) *Image {
+	f := newThinFrame(b)
+
+	var ends []int
+	for i, v := range f.pix {
+		if v != 0 && isEndpoint(f.mask(i)) {
+			ends = append(ends, i)
+		}
+	}
+
+	// neighbours are followed edge neighbours first, so that no pixel of
+	// the path is cut through a corner.
+	order := [8]int{0, 2, 4, 6, 1, 3, 5, 7}
+	var spurs, path []int
+	for _, e := range ends {
+		path = append(path[:0], e)
+		for cur := e; len(path) < n; {
+			next := -1
+			for _, k := range order {
+				j := cur + f.ring[k]
+				if f.pix[j] != 0 && !containsInt(path, j) {
+					next = j
+					break
+				}
+			}
+			if next == -1 {
+				// isolated path
+				break
+			}
+			if isBranchPoint(f.mask(next)) {
+				spurs = append(spurs, path...)
+				break
+			}
+			path = append(path, next)
+			cur = next
+		}
+	}
+	for _, i := range spurs {
+		f.pix[i] = 0
+	}
+	return f.image(b)
+}
+
+// containsInt reports whether s contains v.
+func containsInt(s []int, v int) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package binimg
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+// isThin reports whether b doesn't contain any 2x2 square of On pixels.
+func isThin(b *Image) bool {
+	for y := b.Rect.Min.Y; y < b.Rect.Max.Y-1; y++ {
+		for x := b.Rect.Min.X; x < b.Rect.Max.X-1; x++ {
+			if b.BitAt(x, y) == On && b.BitAt(x+1, y) == On && b.BitAt(x, y+1) == On && b.BitAt(x+1, y+1) == On {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestThin(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/bwgopher.png")
+	test.Check(t, err)
+	gopher := Not(NewFromImage(src))
+
+	var tests = []*Image{
+		newFromString(image.Pt(-3, 2), []string{
+			"000000000000",
+			"011111111110",
+			"011111111110",
+			"011111111110",
+			"011111111110",
+			"000000000000",
+		}),
+		newFromString(image.Pt(0, 0), []string{
+			"0001110000",
+			"0001110000",
+			"1111111111",
+			"1111111111",
+			"1111111111",
+			"0001110000",
+			"0001110000",
+			"0001110000",
+		}),
+		gopher,
+	}
+
+	for _, alg := range []ThinningAlgorithm{ZhangSuen, GuoHall} {
+		for i, b := range tests {
+			skel := Thin(b, alg)
+			if skel.Rect != b.Rect {
+				t.Fatalf("alg %d, test %d: want bounds %v, got %v", alg, i, b.Rect, skel.Rect)
+			}
+			if !isThin(skel) {
+				t.Errorf("alg %d, test %d: want skeleton without 2x2 On squares", alg, i)
+			}
+			// the skeleton is a subset of the original image
+			if err := test.Diff(skel, Combine(skel, b, And)); err != nil {
+				t.Errorf("alg %d, test %d: skeleton is not a subset of the image: %v", alg, i, err)
+			}
+			// connectivity is preserved
+			if want, got := len(Label(b, Connectivity8).Components), len(Label(skel, Connectivity8).Components); want != got {
+				t.Errorf("alg %d, test %d: want %d connected components, got %d", alg, i, want, got)
+			}
+			// thinning a skeleton doesn't modify it
+			if err := test.Diff(skel, Thin(skel, alg)); err != nil {
+				t.Errorf("alg %d, test %d: thinning is not idempotent: %v", alg, i, err)
+			}
+		}
+	}
+}
+
+func TestThinCross(t *testing.T) {
+	b := newFromString(image.Pt(0, 0), []string{
+		"00000111000000",
+		"00000111000000",
+		"00000111000000",
+		"00000111000000",
+		"11111111111111",
+		"11111111111111",
+		"11111111111111",
+		"00000111000000",
+		"00000111000000",
+		"00000111000000",
+		"00000111000000",
+	})
+	for _, alg := range []ThinningAlgorithm{ZhangSuen, GuoHall} {
+		skel := Thin(b, alg)
+		if n := len(Endpoints(skel)); n != 4 {
+			t.Errorf("alg %d: want 4 endpoints, got %d", alg, n)
+		}
+		if n := len(BranchPoints(skel)); n == 0 {
+			t.Errorf("alg %d: want branch points, got none", alg)
+		}
+	}
+}
+
+func TestEndpointsBranchPoints(t *testing.T) {
+	b := newFromString(image.Pt(10, 20), []string{
+		"0000000000",
+		"0111111110",
+		"0000100000",
+		"0000100000",
+		"0000010000",
+		"0000000000",
+		"0000001100",
+	})
+
+	wantEnds := []image.Point{{11, 21}, {18, 21}, {15, 24}, {16, 26}, {17, 26}}
+	if got := Endpoints(b); !reflect.DeepEqual(got, wantEnds) {
+		t.Errorf("Endpoints: want %v, got %v", wantEnds, got)
+	}
+	wantBranches := []image.Point{{14, 21}}
+	if got := BranchPoints(b); !reflect.DeepEqual(got, wantBranches) {
+		t.Errorf("BranchPoints: want %v, got %v", wantBranches, got)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	b := newFromString(image.Pt(0, 0), []string{
+		"00000000000000",
+		"01111111111110",
+		"00001000000000",
+		"00001000100000",
+		"00001000010000",
+		"00000100001000",
+		"00000000000000",
+	})
+
+	var tests = []struct {
+		n    int
+		want []string
+	}{
+		{
+			// the shortest spur is 3 pixels long, nothing is removed
+			n: 3,
+			want: []string{
+				"00000000000000",
+				"01111111111110",
+				"00001000000000",
+				"00001000100000",
+				"00001000010000",
+				"00000100001000",
+				"00000000000000",
+			},
+		},
+		{
+			n: 4,
+			want: []string{
+				"00000000000000",
+				"00001111111110",
+				"00001000000000",
+				"00001000100000",
+				"00001000010000",
+				"00000100001000",
+				"00000000000000",
+			},
+		},
+		{
+			// the isolated diagonal line is kept
+			n: 5,
+			want: []string{
+				"00000000000000",
+				"00001111111110",
+				"00000000000000",
+				"00000000100000",
+				"00000000010000",
+				"00000000001000",
+				"00000000000000",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got := Prune(b, tt.n)
+		if err := test.Diff(newFromString(image.Pt(0, 0), tt.want), got); err != nil {
+			t.Errorf("Prune(%d): %v", tt.n, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+)
+
+type alphaScanner struct {
+	*image.Alpha
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// The scan stops at the first pixel encountered that is different from c.
+func (s *alphaScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	var (
+		ok    bool        // conversion to color.Alpha ok
+		alpha color.Alpha // c converted to Alpha
+	)
+	// ensure c is a color.Alpha, or convert it
+	if alpha, ok = c.(color.Alpha); !ok {
+		alpha = s.ColorModel().Convert(c).(color.Alpha)
+	}
+
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	return isUniformRegion(s.Pix, i, r.Dx(), r.Dy(), s.Stride, alpha.A)
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// The scan stops at the first pixel encountered that is different from the
+// previous one.
+func (s *alphaScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	// color of the first pixel (top-left)
+	first := s.AlphaAt(r.Min.X, r.Min.Y)
+
+	// check if all the pixels of the region are of this color.
+	if s.IsUniformColor(r, first) {
+		return true, first
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// A full scan of the region is performed in order to determine the average
+// color.
+func (s *alphaScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+
+	var sum uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := s.PixOffset(r.Min.X, y)
+		for _, v := range s.Pix[i : i+r.Dx()] {
+			sum += uint64(v)
+		}
+	}
+	return false, color.Alpha{uint8(sum / uint64(r.Dx()*r.Dy()))}
+}
+
+// NewAlphaScanner creates an Alpha scanner from an Alpha image.
+func NewAlphaScanner(img *image.Alpha) Scanner {
+	return &alphaScanner{img}
+}
+
+type alpha16Scanner struct {
+	*image.Alpha16
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// The scan stops at the first pixel encountered that is different from c.
+func (s *alpha16Scanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	var (
+		ok      bool          // conversion to color.Alpha16 ok
+		alpha16 color.Alpha16 // c converted to Alpha16
+	)
+	// ensure c is a color.Alpha16, or convert it
+	if alpha16, ok = c.(color.Alpha16); !ok {
+		alpha16 = s.ColorModel().Convert(c).(color.Alpha16)
+	}
+
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	px := []byte{uint8(alpha16.A >> 8), uint8(alpha16.A)}
+	return isUniformPixels(s.Pix, i, r.Dx(), r.Dy(), s.Stride, px, nil)
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// The scan stops at the first pixel encountered that is different from the
+// previous one.
+func (s *alpha16Scanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	// color of the first pixel (top-left)
+	first := s.Alpha16At(r.Min.X, r.Min.Y)
+
+	// check if all the pixels of the region are of this color.
+	if s.IsUniformColor(r, first) {
+		return true, first
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// A full scan of the region is performed in order to determine the average
+// color.
+func (s *alpha16Scanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+
+	var sum uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := s.PixOffset(r.Min.X, y)
+		row := s.Pix[i : i+2*r.Dx()]
+		for j := 0; j < len(row); j += 2 {
+			sum += uint64(row[j])<<8 | uint64(row[j+1])
+		}
+	}
+	return false, color.Alpha16{uint16(sum / uint64(r.Dx()*r.Dy()))}
+}
+
+// NewAlpha16Scanner creates an Alpha16 scanner from an Alpha16 image.
+func NewAlpha16Scanner(img *image.Alpha16) Scanner {
+	return &alpha16Scanner{img}
+}
@@ -0,0 +1,53 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestAlphaScanner(t *testing.T) {
+	img := image.NewAlpha(image.Rect(0, 0, 3, 2))
+	copy(img.Pix, []byte{
+		0, 0, 255,
+		0, 10, 255,
+	})
+	scanner, err := NewScanner(img)
+	test.Check(t, err)
+
+	checkIsUniformColor(t, scanner, []regionTest{
+		{0, 0, 2, 1, color.Transparent, true},
+		{0, 0, 1, 2, color.Alpha{0}, true},
+		{0, 0, 2, 2, color.Transparent, false},
+		{2, 0, 3, 2, color.Opaque, true},
+		{2, 0, 3, 2, color.Black, true},
+		{1, 1, 2, 2, color.Alpha16{0x0a0a}, true},
+	})
+	checkAverageColor(t, scanner, []regionTest{
+		{2, 0, 3, 2, color.Alpha{255}, true},
+		{0, 0, 3, 2, color.Alpha{86}, false},
+	})
+}
+
+func TestAlpha16Scanner(t *testing.T) {
+	img := image.NewAlpha16(image.Rect(0, 0, 3, 2))
+	for i, v := range []uint16{0, 0, 0xffff, 0, 0x0001, 0xffff} {
+		img.SetAlpha16(i%3, i/3, color.Alpha16{v})
+	}
+	scanner, err := NewScanner(img)
+	test.Check(t, err)
+
+	checkIsUniformColor(t, scanner, []regionTest{
+		{0, 0, 2, 1, color.Transparent, true},
+		{0, 0, 2, 2, color.Transparent, false},
+		{2, 0, 3, 2, color.Opaque, true},
+		{2, 0, 3, 2, color.Alpha{255}, true},
+		{1, 1, 2, 2, color.Alpha16{1}, true},
+	})
+	checkAverageColor(t, scanner, []regionTest{
+		{2, 0, 3, 2, color.Alpha16{0xffff}, true},
+		{0, 0, 3, 2, color.Alpha16{0x5555}, false},
+	})
+}
@@ -1,7 +1,6 @@
 package imgscan
 
 import (
-	"bytes"
 	"image"
 	"image/color"
 
@@ -16,27 +15,11 @@ type binaryScanner struct {
 //
 // The scan stops at the first pixel encountered that is different from c.
 func (s *binaryScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
-	var (
-		ok  bool
-		bit binimg.Bit
-	)
-	// ensure c is a binimg.Bit, or convert it
-	if bit, ok = c.(binimg.Bit); !ok {
-		bit = s.ColorModel().Convert(c).(binimg.Bit)
-	}
+	// ensure c is a binimg.Bit, or convert it with the image palette
+	bit := s.Palette.Bit(c)
 
-	// in a binary image, pixel/bytes are 1 or 0, we want the other color for
-	// bytes.IndexBytes
-	other := bit.Other().V
-	for y := r.Min.Y; y < r.Max.Y; y++ {
-		i := s.PixOffset(r.Min.X, y)
-		j := s.PixOffset(r.Max.X, y)
-		// look for the first pixel that is not c
-		if bytes.IndexByte(s.Pix[i:j], other) != -1 {
-			return false
-		}
-	}
-	return true
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	return isUniformRegion(s.Pix, i, r.Dx(), r.Dy(), s.Stride, bit.V)
 }
 
 // IsUniform indicates if the region r is uniform. If that is the case, the
@@ -50,7 +33,7 @@ func (s *binaryScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
 
 	// check if all the pixels of the region are of this color.
 	if s.IsUniformColor(r, first) {
-		return true, first
+		return true, s.Palette.Color(first)
 	}
 	return false, nil
 }
@@ -59,6 +42,9 @@ func (s *binaryScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
 // of the region r. If all the pixels have the same color (i.e the region is
 // uniform) then the average color is that color.
 //
+// If the region is not uniform, the average color is the color of the
+// majority of its pixels, ties being resolved as binimg.On.
+//
 // A full scan of the region is performed in order to determine the average
 // color.
 func (s *binaryScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
@@ -67,11 +53,40 @@ func (s *binaryScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
 		// return its color
 		return true, col
 	}
-	// or consider the whole region as made of On pixel (arbitrary)
-	return false, binimg.On
+	if 2*s.countOn(r) >= r.Dx()*r.Dy() {
+		return false, s.Palette.Color(binimg.On)
+	}
+	return false, s.Palette.Color(binimg.Off)
+}
+
+// OnRatio returns the proportion of On pixels in the region r, between 0 (no
+// On pixels) and 1 (only On pixels). The ratio of an empty region is 0.
+//
+// A full scan of the region is performed in order to determine the ratio.
+func (s *binaryScanner) OnRatio(r image.Rectangle) float64 {
+	n := r.Dx() * r.Dy()
+	if n <= 0 {
+		return 0
+	}
+	return float64(s.countOn(r)) / float64(n)
+}
+
+// countOn returns the number of On pixels in the region r.
+func (s *binaryScanner) countOn(r image.Rectangle) int {
+	var count int
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := s.PixOffset(r.Min.X, y)
+		j := s.PixOffset(r.Max.X, y)
+		for _, v := range s.Pix[i:j] {
+			if v != binimg.Off.V {
+				count++
+			}
+		}
+	}
+	return count
 }
 
 // NewBinaryScanner creates a binary scanner from a binary image.
-func NewBinaryScanner(img *binimg.Image) Scanner {
+func NewBinaryScanner(img *binimg.Image) BinaryScanner {
 	return &binaryScanner{img}
 }
@@ -1,6 +1,7 @@
 package imgscan
 
 import (
+	"bytes"
 	"image"
 	"image/color"
 	"testing"
@@ -122,13 +123,15 @@ func TestBinaryScannerAverageColor(t *testing.T) {
 		col                    color.Color
 		uniform                bool
 	}{
-		{0, 0, 3, 3, binimg.On, false},
+		{0, 0, 3, 3, binimg.Off, false},
 		{1, 1, 3, 3, binimg.On, false},
 		{0, 1, 1, 2, binimg.On, true},
 		{0, 0, 1, 1, binimg.Off, true},
 		{1, 0, 2, 1, binimg.Off, true},
 		{1, 0, 3, 2, binimg.Off, true},
 		{1, 1, 2, 3, binimg.On, false},
+		{0, 0, 2, 3, binimg.Off, false},
+		{0, 1, 3, 3, binimg.On, false},
 		{1, 2, 3, 3, binimg.On, true},
 		{2, 2, 3, 3, binimg.On, true},
 	}
@@ -146,6 +149,65 @@ func TestBinaryScannerAverageColor(t *testing.T) {
 	}
 }
 
+func TestBinaryScannerOnRatio(t *testing.T) {
+	ss := []string{
+		"000",
+		"100",
+		"011",
+	}
+
+	var tests = []struct {
+		minx, miny, maxx, maxy int
+		ratio                  float64
+	}{
+		{0, 0, 3, 3, 3. / 9},
+		{1, 1, 3, 3, 2. / 4},
+		{0, 1, 1, 2, 1},
+		{0, 0, 1, 1, 0},
+		{1, 0, 3, 2, 0},
+		{0, 1, 3, 3, 3. / 6},
+		{0, 0, 0, 0, 0},
+	}
+
+	scanner := NewBinaryScanner(newBinaryFromString(ss))
+	for _, tt := range tests {
+		ratio := scanner.OnRatio(image.Rect(tt.minx, tt.miny, tt.maxx, tt.maxy))
+		if ratio != tt.ratio {
+			t.Errorf("want ratio=%v for OnRatio(rect{%d,%d|%d,%d}), got %v", tt.ratio, tt.minx, tt.miny, tt.maxx, tt.maxy, ratio)
+		}
+	}
+}
+
+func TestBinaryScannerPalette(t *testing.T) {
+	ss := []string{
+		"000",
+		"100",
+		"011",
+	}
+
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+	img := newBinaryFromString(ss)
+	img.Palette = &binimg.Palette{OnColor: blue, OffColor: red}
+	scanner, err := NewScanner(img)
+	test.Check(t, err)
+
+	if !scanner.IsUniformColor(image.Rect(1, 0, 3, 2), red) {
+		t.Errorf("want region of Off pixels uniform of the Off color")
+	}
+	if !scanner.IsUniformColor(image.Rect(1, 0, 3, 2), binimg.Off) {
+		t.Errorf("want region of Off pixels uniform of binimg.Off")
+	}
+	if scanner.IsUniformColor(image.Rect(1, 0, 3, 2), blue) {
+		t.Errorf("want region of Off pixels not uniform of the On color")
+	}
+	if uniform, col := scanner.IsUniform(image.Rect(1, 2, 3, 3)); !uniform || col != blue {
+		t.Errorf("want region of On pixels uniform of the On color, got uniform=%v, color=%v", uniform, col)
+	}
+	if uniform, col := scanner.AverageColor(image.Rect(0, 0, 3, 3)); uniform || col != red {
+		t.Errorf("want average color of mostly Off region to be the Off color, got uniform=%v, color=%v", uniform, col)
+	}
+}
+
 func benchmarkScanner(b *testing.B, pngfile string, newScanner func(image.Image) Scanner) {
 	img, err := test.LoadPNG(pngfile)
 	test.CheckB(b, err)
@@ -162,10 +224,81 @@ func benchmarkScanner(b *testing.B, pngfile string, newScanner func(image.Image)
 }
 
 func BenchmarkLinesScanner(b *testing.B) {
-	benchmarkScanner(b, "./testdata/big.png",
+	benchmarkScanner(b, "../testdata/big.png",
 		func(img image.Image) Scanner {
 			s, err := NewScanner(img)
 			test.CheckB(b, err)
 			return s
 		})
 }
+
+// indexByteIsUniform is the line per line, bytes.IndexByte based, uniformity
+// check of binary images, used as a baseline for benchmarks.
+func indexByteIsUniform(pix []byte, i, w, h, stride int, v byte) bool {
+	other := binimg.Bit{V: v}.Other().V
+	for y := 0; y < h; y++ {
+		if bytes.IndexByte(pix[i:i+w], other) != -1 {
+			return false
+		}
+		i += stride
+	}
+	return true
+}
+
+// uniformTiles is the number of uniform tiles found by the last run of
+// benchmarkUniformTiles, it prevents the scans from being optimized away.
+var uniformTiles int
+
+// benchmarkUniformTiles benchmarks the uniformity check of all the square
+// tiles of side size of a pixel buffer, each tile being compared to the value
+// of its first pixel. If size is 0, the whole buffer is scanned at once.
+func benchmarkUniformTiles(b *testing.B, pix []byte, r image.Rectangle, stride, size int,
+	isUniform func(pix []byte, i, w, h, stride int, v byte) bool) {
+	w, h := size, size
+	if size == 0 {
+		w, h = r.Dx(), r.Dy()
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		uniformTiles = 0
+		for y := 0; y+h <= r.Dy(); y += h {
+			for x := 0; x+w <= r.Dx(); x += w {
+				i := y*stride + x
+				if isUniform(pix, i, w, h, stride, pix[i]) {
+					uniformTiles++
+				}
+			}
+		}
+	}
+}
+
+// benchmarkUniformBinary benchmarks isUniform on the tiles of big.png
+// converted to a binary image, and on the tiles of an uniform image of the
+// same size, the worst case where all the pixels are scanned.
+func benchmarkUniformBinary(b *testing.B, size int, isUniform func(pix []byte, i, w, h, stride int, v byte) bool) {
+	img, err := test.LoadPNG("../testdata/big.png")
+	test.CheckB(b, err)
+
+	bin := binimg.NewFromImage(img)
+	b.Run("big.png", func(b *testing.B) {
+		benchmarkUniformTiles(b, bin.Pix, bin.Rect, bin.Stride, size, isUniform)
+	})
+
+	uniform := binimg.New(img.Bounds())
+	uniform.SetRect(uniform.Bounds(), binimg.On)
+	b.Run("uniform", func(b *testing.B) {
+		benchmarkUniformTiles(b, uniform.Pix, uniform.Rect, uniform.Stride, size, isUniform)
+	})
+}
+
+func BenchmarkUniformBinaryIndexByte(b *testing.B) { benchmarkUniformBinary(b, 0, indexByteIsUniform) }
+func BenchmarkUniformBinaryWords(b *testing.B)     { benchmarkUniformBinary(b, 0, isUniformRegion) }
+func BenchmarkUniformBinaryTiles16IndexByte(b *testing.B) {
+	benchmarkUniformBinary(b, 16, indexByteIsUniform)
+}
+func BenchmarkUniformBinaryTiles16Words(b *testing.B) { benchmarkUniformBinary(b, 16, isUniformRegion) }
+func BenchmarkUniformBinaryTiles64IndexByte(b *testing.B) {
+	benchmarkUniformBinary(b, 64, indexByteIsUniform)
+}
+func BenchmarkUniformBinaryTiles64Words(b *testing.B) { benchmarkUniformBinary(b, 64, isUniformRegion) }
@@ -0,0 +1,76 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+)
+
+type cmykScanner struct {
+	*image.CMYK
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// The scan stops at the first pixel encountered that is different from c.
+func (s *cmykScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	var (
+		ok   bool       // conversion to color.CMYK ok
+		cmyk color.CMYK // c converted to CMYK
+	)
+	// ensure c is a color.CMYK, or convert it
+	if cmyk, ok = c.(color.CMYK); !ok {
+		cmyk = s.ColorModel().Convert(c).(color.CMYK)
+	}
+
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	px := []byte{cmyk.C, cmyk.M, cmyk.Y, cmyk.K}
+	return isUniformPixels(s.Pix, i, r.Dx(), r.Dy(), s.Stride, px, nil)
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// The scan stops at the first pixel encountered that is different from the
+// previous one.
+func (s *cmykScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	// color of the first pixel (top-left)
+	first := s.CMYKAt(r.Min.X, r.Min.Y)
+
+	// check if all the pixels of the region are of this color.
+	if s.IsUniformColor(r, first) {
+		return true, first
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// A full scan of the region is performed in order to determine the average
+// color. The average is computed in the CMYK space, each channel being
+// averaged independently.
+func (s *cmykScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+
+	var sum [4]uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := s.PixOffset(r.Min.X, y)
+		row := s.Pix[i : i+4*r.Dx()]
+		for j := 0; j < len(row); j += 4 {
+			sum[0] += uint64(row[j])
+			sum[1] += uint64(row[j+1])
+			sum[2] += uint64(row[j+2])
+			sum[3] += uint64(row[j+3])
+		}
+	}
+	n := uint64(r.Dx() * r.Dy())
+	return false, color.CMYK{uint8(sum[0] / n), uint8(sum[1] / n), uint8(sum[2] / n), uint8(sum[3] / n)}
+}
+
+// NewCMYKScanner creates a CMYK scanner from a CMYK image.
+func NewCMYKScanner(img *image.CMYK) Scanner {
+	return &cmykScanner{img}
+}
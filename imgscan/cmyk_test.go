@@ -0,0 +1,32 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestCMYKScanner(t *testing.T) {
+	cyan, white := color.CMYK{255, 0, 0, 0}, color.CMYK{0, 0, 0, 0}
+	img := image.NewCMYK(image.Rect(0, 0, 3, 2))
+	for i, c := range []color.CMYK{cyan, cyan, white, cyan, {0, 0, 0, 255}, white} {
+		img.SetCMYK(i%3, i/3, c)
+	}
+	scanner, err := NewScanner(img)
+	test.Check(t, err)
+
+	checkIsUniformColor(t, scanner, []regionTest{
+		{0, 0, 2, 1, cyan, true},
+		{0, 0, 1, 2, color.RGBA{0, 255, 255, 255}, true},
+		{0, 0, 2, 2, cyan, false},
+		{2, 0, 3, 2, color.White, true},
+		{1, 1, 2, 2, color.Black, true},
+		{1, 1, 2, 2, white, false},
+	})
+	checkAverageColor(t, scanner, []regionTest{
+		{2, 0, 3, 2, white, true},
+		{0, 0, 3, 2, color.CMYK{127, 0, 0, 42}, false},
+	})
+}
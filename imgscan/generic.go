@@ -0,0 +1,94 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+)
+
+type genericScanner struct {
+	image.Image
+}
+
+// rgba64 returns the alpha-premultiplied channels of c.
+func rgba64(c color.Color) color.RGBA64 {
+	r, g, b, a := c.RGBA()
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// c is first converted to the color model of the image, then pixels are
+// compared by their alpha-premultiplied channels, as returned by RGBA.
+//
+// The scan stops at the first pixel encountered that is different from c.
+func (s *genericScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	if u, ok := s.Image.(*image.Uniform); ok {
+		// the bounds of a uniform image are infinite, don't scan them. Its
+		// color model converts any color to its own one, so c isn't
+		// converted.
+		return r.Empty() || rgba64(u.C) == rgba64(c)
+	}
+
+	want := rgba64(s.ColorModel().Convert(c))
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if rgba64(s.At(x, y)) != want {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// The scan stops at the first pixel encountered that is different from the
+// previous one.
+func (s *genericScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	// color of the first pixel (top-left)
+	first := s.At(r.Min.X, r.Min.Y)
+
+	// check if all the pixels of the region are of this color.
+	if s.IsUniformColor(r, first) {
+		return true, first
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// A full scan of the region is performed in order to determine the average
+// color, that is returned as a color.RGBA64 for non uniform regions.
+func (s *genericScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+
+	var sum [4]uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			r, g, b, a := s.At(x, y).RGBA()
+			sum[0] += uint64(r)
+			sum[1] += uint64(g)
+			sum[2] += uint64(b)
+			sum[3] += uint64(a)
+		}
+	}
+	n := uint64(r.Dx() * r.Dy())
+	return false, color.RGBA64{uint16(sum[0] / n), uint16(sum[1] / n), uint16(sum[2] / n), uint16(sum[3] / n)}
+}
+
+// NewGenericScanner creates a scanner of any image.Image, that accesses the
+// pixels one by one, through At and RGBA. It is much slower than the
+// scanners returned by NewScanner, so it should only be used for the images
+// that NewScanner doesn't support.
+//
+// Scanning a region of an image.Uniform doesn't access its pixels, so any
+// region of its infinite bounds can be scanned.
+func NewGenericScanner(img image.Image) Scanner {
+	return &genericScanner{img}
+}
@@ -0,0 +1,116 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+// checkerboard is a custom draw.Image, made of 4x4 squares whose colors can
+// be overridden.
+type checkerboard struct {
+	r   image.Rectangle
+	set map[image.Point]color.Color
+}
+
+func (c *checkerboard) ColorModel() color.Model { return color.RGBAModel }
+func (c *checkerboard) Bounds() image.Rectangle { return c.r }
+func (c *checkerboard) Set(x, y int, col color.Color) {
+	c.set[image.Pt(x, y)] = c.ColorModel().Convert(col)
+}
+
+func (c *checkerboard) At(x, y int) color.Color {
+	if col, ok := c.set[image.Pt(x, y)]; ok {
+		return col
+	}
+	if (x/4+y/4)%2 == 0 {
+		return color.RGBA{0, 0, 0, 255}
+	}
+	return color.RGBA{255, 255, 255, 255}
+}
+
+func TestGenericScannerCustomImage(t *testing.T) {
+	var img draw.Image = &checkerboard{image.Rect(0, 0, 8, 8), make(map[image.Point]color.Color)}
+	img.Set(7, 7, color.NRGBA{255, 0, 0, 255})
+	scanner := NewScannerWithFallback(img)
+
+	checkIsUniformColor(t, scanner, []regionTest{
+		{0, 0, 4, 4, color.Black, true},
+		{4, 0, 8, 4, color.White, true},
+		{0, 0, 5, 4, color.Black, false},
+		{4, 4, 7, 7, color.Gray{0}, true},
+		{4, 4, 8, 8, color.Black, false},
+		{7, 7, 8, 8, color.RGBA{255, 0, 0, 255}, true},
+	})
+	checkAverageColor(t, scanner, []regionTest{
+		{4, 0, 8, 4, color.RGBA{255, 255, 255, 255}, true},
+		{0, 0, 8, 4, color.RGBA64{0x7fff, 0x7fff, 0x7fff, 0xffff}, false},
+		{6, 7, 8, 8, color.RGBA64{0x7fff, 0, 0, 0xffff}, false},
+	})
+}
+
+func TestGenericScannerUniform(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	scanner := NewScannerWithFallback(image.NewUniform(red))
+
+	// huge regions are not scanned
+	checkIsUniformColor(t, scanner, []regionTest{
+		{-1 << 30, -1 << 30, 1 << 30, 1 << 30, red, true},
+		{-1 << 30, -1 << 30, 1 << 30, 1 << 30, color.RGBA{255, 0, 0, 255}, true},
+		{-1 << 30, -1 << 30, 1 << 30, 1 << 30, color.White, false},
+	})
+	checkAverageColor(t, scanner, []regionTest{
+		{-1 << 30, -1 << 30, 1 << 30, 1 << 30, red, true},
+	})
+}
+
+func TestGenericScanner(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/colorgopher.png")
+	test.Check(t, err)
+	b := src.Bounds()
+	img := image.NewRGBA(b)
+	draw.Draw(img, b, src, b.Min, draw.Src)
+
+	// compare with the RGBA scanner
+	want := NewRGBAScanner(img)
+	got := NewGenericScanner(img)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		x, y := b.Min.X+rnd.Intn(b.Dx()-1), b.Min.Y+rnd.Intn(b.Dy()-1)
+		r := image.Rect(x, y, x+1+rnd.Intn(10), y+1+rnd.Intn(10)).Intersect(b)
+
+		wu, wc := want.IsUniform(r)
+		gu, gc := got.IsUniform(r)
+		if wu != gu || wc != gc {
+			t.Fatalf("IsUniform(%v): want (%v, %v), got (%v, %v)", r, wu, wc, gu, gc)
+		}
+		for _, c := range []color.Color{color.Transparent, color.White, wc} {
+			if c == nil {
+				continue
+			}
+			if w, g := want.IsUniformColor(r, c), got.IsUniformColor(r, c); w != g {
+				t.Fatalf("IsUniformColor(%v, %v): want %v, got %v", r, c, w, g)
+			}
+		}
+		if wu {
+			continue
+		}
+		_, wc = want.AverageColor(r)
+		_, gc = got.AverageColor(r)
+		wrgba := wc.(color.RGBA)
+		grgba := gc.(color.RGBA64)
+		// 8-bit and 16-bit averages differ by truncation only
+		for _, ch := range [][2]uint32{
+			{uint32(wrgba.R), uint32(grgba.R)}, {uint32(wrgba.G), uint32(grgba.G)},
+			{uint32(wrgba.B), uint32(grgba.B)}, {uint32(wrgba.A), uint32(grgba.A)},
+		} {
+			if d := int(ch[0]) - int(ch[1]/0x101); d < -1 || d > 1 {
+				t.Fatalf("AverageColor(%v): want %v, got %v", r, wc, gc)
+			}
+		}
+	}
+}
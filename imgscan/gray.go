@@ -1,7 +1,6 @@
 package imgscan
 
 import (
-	"bytes"
 	"image"
 	"image/color"
 )
@@ -17,25 +16,14 @@ func (s *grayScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
 	var (
 		ok   bool       // conversion to color.Gray ok
 		gray color.Gray // c converted to Gray
-		b    uint8      // gray level
-		last int        // index of last byte to check on the line
 	)
 	// ensure c is a color.Gray, or convert it
 	if gray, ok = c.(color.Gray); !ok {
 		gray = s.ColorModel().Convert(c).(color.Gray)
 	}
-	b, last = gray.Y, r.Max.X-r.Min.X-1
 
-	for y := r.Min.Y; y < r.Max.Y; y++ {
-		i := s.PixOffset(r.Min.X, y)
-		j := s.PixOffset(r.Max.X, y)
-		// check the first and the last pixel/byte of color c are respectively
-		// the first and last pixels of the slice.
-		if bytes.IndexByte(s.Pix[i:j], b) != 0 || bytes.LastIndexByte(s.Pix[i:j], b) != last {
-			return false
-		}
-	}
-	return true
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	return isUniformRegion(s.Pix, i, r.Dx(), r.Dy(), s.Stride, gray.Y)
 }
 
 // IsUniform indicates if the region r is uniform. If that is the case, the
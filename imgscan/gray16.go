@@ -0,0 +1,71 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+)
+
+type gray16Scanner struct {
+	*image.Gray16
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// The scan stops at the first pixel encountered that is different from c.
+func (s *gray16Scanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	var (
+		ok     bool         // conversion to color.Gray16 ok
+		gray16 color.Gray16 // c converted to Gray16
+	)
+	// ensure c is a color.Gray16, or convert it
+	if gray16, ok = c.(color.Gray16); !ok {
+		gray16 = s.ColorModel().Convert(c).(color.Gray16)
+	}
+
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	px := []byte{uint8(gray16.Y >> 8), uint8(gray16.Y)}
+	return isUniformPixels(s.Pix, i, r.Dx(), r.Dy(), s.Stride, px, nil)
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// The scan stops at the first pixel encountered that is different from the
+// previous one.
+func (s *gray16Scanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	// color of the first pixel (top-left)
+	first := s.Gray16At(r.Min.X, r.Min.Y)
+
+	// check if all the pixels of the region are of this color.
+	if s.IsUniformColor(r, first) {
+		return true, first
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// A full scan of the region is performed in order to determine the average
+// color.
+func (s *gray16Scanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+
+	var sum uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := s.PixOffset(r.Min.X, y)
+		row := s.Pix[i : i+2*r.Dx()]
+		for j := 0; j < len(row); j += 2 {
+			sum += uint64(row[j])<<8 | uint64(row[j+1])
+		}
+	}
+	return false, color.Gray16{uint16(sum / uint64(r.Dx()*r.Dy()))}
+}
+
+// NewGray16Scanner creates a Gray16 scanner from a Gray16 image.
+func NewGray16Scanner(img *image.Gray16) Scanner {
+	return &gray16Scanner{img}
+}
@@ -0,0 +1,33 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestGray16Scanner(t *testing.T) {
+	img := image.NewGray16(image.Rect(0, 0, 3, 2))
+	for i, v := range []uint16{0x1234, 0x1234, 0x1234, 0x1234, 0x1235, 0xffff} {
+		img.SetGray16(i%3, i/3, color.Gray16{v})
+	}
+	scanner, err := NewScanner(img)
+	test.Check(t, err)
+
+	checkIsUniformColor(t, scanner, []regionTest{
+		{0, 0, 3, 1, color.Gray16{0x1234}, true},
+		{0, 0, 1, 2, color.Gray16{0x1234}, true},
+		{0, 0, 2, 2, color.Gray16{0x1234}, false},
+		// only the low byte differs
+		{1, 1, 2, 2, color.Gray16{0x1234}, false},
+		{2, 1, 3, 2, color.White, true},
+		{2, 1, 3, 2, color.Gray{0xff}, true},
+	})
+	checkAverageColor(t, scanner, []regionTest{
+		{0, 0, 3, 1, color.Gray16{0x1234}, true},
+		{0, 0, 2, 2, color.Gray16{0x1234}, false},
+		{1, 1, 3, 2, color.Gray16{0x891a}, false},
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/csv"
 	"image"
 	"image/color"
+	"image/draw"
 	"log"
 	"strconv"
 	"strings"
@@ -62,6 +63,8 @@ func TestGrayScannerIsUniformColor(t *testing.T) {
 		{0, 1, 1, 2, color.Gray{122}, true},
 		{1, 2, 3, 3, color.Gray{24}, true},
 		{1, 2, 3, 3, color.Gray{127}, false},
+		{0, 0, 3, 2, color.Gray{0}, false},
+		{1, 0, 3, 2, color.Gray{0}, true},
 	}
 
 	img := newGrayFromString(ss)
@@ -141,3 +144,28 @@ func TestGrayScannerAverageColor(t *testing.T) {
 		}
 	}
 }
+
+// benchmarkUniformGray benchmarks the uniformity check of the tiles of
+// big.png converted to a gray image, and of the tiles of an uniform image of
+// the same size, the worst case where all the pixels are scanned.
+func benchmarkUniformGray(b *testing.B, size int) {
+	img, err := test.LoadPNG("../testdata/big.png")
+	test.CheckB(b, err)
+
+	gray := image.NewGray(img.Bounds())
+	draw.Draw(gray, gray.Rect, img, img.Bounds().Min, draw.Src)
+	b.Run("big.png", func(b *testing.B) {
+		benchmarkUniformTiles(b, gray.Pix, gray.Rect, gray.Stride, size, isUniformRegion)
+	})
+
+	uniform := image.NewGray(img.Bounds())
+	for i := range uniform.Pix {
+		uniform.Pix[i] = 127
+	}
+	b.Run("uniform", func(b *testing.B) {
+		benchmarkUniformTiles(b, uniform.Pix, uniform.Rect, uniform.Stride, size, isUniformRegion)
+	})
+}
+
+func BenchmarkUniformGray(b *testing.B)        { benchmarkUniformGray(b, 0) }
+func BenchmarkUniformGrayTiles16(b *testing.B) { benchmarkUniformGray(b, 16) }
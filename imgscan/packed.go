@@ -0,0 +1,129 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+type packedScanner struct {
+	*binimg.Packed
+}
+
+// lineMasks returns, for the lines of the region r, the offset n between the
+// first and the last byte of a line, and the masks of the bits of r in these
+// bytes. If the line fits in a single byte (n == 0), head is the mask of its
+// bits.
+func lineMasks(r image.Rectangle) (n int, head, tail uint8) {
+	first, last := r.Min.X>>3, (r.Max.X-1)>>3
+	head, tail = uint8(0xff>>uint(r.Min.X&7)), uint8(0xff<<uint(7-((r.Max.X-1)&7)))
+	if first == last {
+		head &= tail
+	}
+	return last - first, head, tail
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// The partial bytes at both ends of each line are checked with masks, the
+// bytes in between being checked a word at a time.
+//
+// The scan stops at the first pixel encountered that is different from c.
+func (s *packedScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	if r.Empty() {
+		return true
+	}
+	// ensure c is a binimg.Bit, or convert it with the image palette
+	var v byte
+	if s.Palette.Bit(c) == binimg.On {
+		v = 0xff
+	}
+
+	n, head, tail := lineMasks(r)
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		if (s.Pix[i]^v)&head != 0 {
+			return false
+		}
+		if n > 0 {
+			if (s.Pix[i+n]^v)&tail != 0 || !isUniformBytes(s.Pix[i+1:i+n], v) {
+				return false
+			}
+		}
+		i += s.Stride
+	}
+	return true
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// The scan stops at the first pixel encountered that is different from the
+// previous one.
+func (s *packedScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	// bit color of the first pixel (top-left)
+	first := s.BitAt(r.Min.X, r.Min.Y)
+
+	// check if all the pixels of the region are of this color.
+	if s.IsUniformColor(r, first) {
+		return true, s.Palette.Color(first)
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// If the region is not uniform, the average color is the color of the
+// majority of its pixels, ties being resolved as binimg.On.
+//
+// A full scan of the region is performed in order to determine the average
+// color.
+func (s *packedScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+	if 2*s.countOn(r) >= r.Dx()*r.Dy() {
+		return false, s.Palette.Color(binimg.On)
+	}
+	return false, s.Palette.Color(binimg.Off)
+}
+
+// OnRatio returns the proportion of On pixels in the region r, between 0 (no
+// On pixels) and 1 (only On pixels). The ratio of an empty region is 0.
+//
+// A full scan of the region is performed in order to determine the ratio.
+func (s *packedScanner) OnRatio(r image.Rectangle) float64 {
+	n := r.Dx() * r.Dy()
+	if n <= 0 {
+		return 0
+	}
+	return float64(s.countOn(r)) / float64(n)
+}
+
+// countOn returns the number of On pixels in the region r, counting the set
+// bits a word at a time.
+func (s *packedScanner) countOn(r image.Rectangle) int {
+	if r.Empty() {
+		return 0
+	}
+	var count int
+	n, head, tail := lineMasks(r)
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		count += bits.OnesCount8(s.Pix[i] & head)
+		if n > 0 {
+			count += bits.OnesCount8(s.Pix[i+n]&tail) + popCount(s.Pix[i+1:i+n])
+		}
+		i += s.Stride
+	}
+	return count
+}
+
+// NewPackedScanner creates a binary scanner from a packed binary image.
+func NewPackedScanner(img *binimg.Packed) BinaryScanner {
+	return &packedScanner{img}
+}
@@ -0,0 +1,73 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/arl/imgtools/binimg"
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestPackedScanner(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/bwgopher.png")
+	test.Check(t, err)
+	bin := binimg.NewFromImage(src)
+
+	// compare with the byte-per-pixel binary scanner, on the whole image
+	// and on a sub-image that doesn't start on a byte boundary
+	want := NewBinaryScanner(bin)
+	packed := binimg.Pack(bin)
+	for _, img := range []image.Image{packed, packed.SubImage(image.Rect(13, 7, 470, 475))} {
+		got, err := NewScanner(img)
+		test.Check(t, err)
+		b := img.Bounds()
+
+		rnd := rand.New(rand.NewSource(1))
+		for i := 0; i < 2000; i++ {
+			x, y := b.Min.X+rnd.Intn(b.Dx()-1), b.Min.Y+rnd.Intn(b.Dy()-1)
+			r := image.Rect(x, y, x+1+rnd.Intn(b.Max.X-x), y+1+rnd.Intn(b.Max.Y-y))
+			if i%2 == 0 {
+				// smaller regions, more likely uniform
+				r = image.Rect(x, y, x+1+rnd.Intn(20), y+1+rnd.Intn(10)).Intersect(b)
+			}
+
+			wu, wc := want.IsUniform(r)
+			gu, gc := got.IsUniform(r)
+			if wu != gu || wc != gc {
+				t.Fatalf("IsUniform(%v): want (%v, %v), got (%v, %v)", r, wu, wc, gu, gc)
+			}
+			for _, c := range []color.Color{binimg.On, color.Black} {
+				if w, g := want.IsUniformColor(r, c), got.IsUniformColor(r, c); w != g {
+					t.Fatalf("IsUniformColor(%v, %v): want %v, got %v", r, c, w, g)
+				}
+			}
+			wu, wc = want.AverageColor(r)
+			gu, gc = got.AverageColor(r)
+			if wu != gu || wc != gc {
+				t.Fatalf("AverageColor(%v): want (%v, %v), got (%v, %v)", r, wu, wc, gu, gc)
+			}
+			if w, g := want.OnRatio(r), got.(BinaryScanner).OnRatio(r); w != g {
+				t.Fatalf("OnRatio(%v): want %v, got %v", r, w, g)
+			}
+		}
+	}
+}
+
+func TestPackedScannerSingleByte(t *testing.T) {
+	p := binimg.NewPacked(image.Rect(0, 0, 16, 1))
+	p.SetRect(image.Rect(2, 0, 6, 1), binimg.On)
+	scanner := NewPackedScanner(p)
+
+	checkIsUniformColor(t, scanner, []regionTest{
+		{2, 0, 6, 1, binimg.On, true},
+		{1, 0, 6, 1, binimg.On, false},
+		{2, 0, 7, 1, binimg.On, false},
+		{6, 0, 16, 1, binimg.Off, true},
+		{0, 0, 2, 1, color.Black, true},
+	})
+	if got := scanner.OnRatio(image.Rect(0, 0, 8, 1)); got != 0.5 {
+		t.Errorf("want OnRatio 0.5, got %v", got)
+	}
+}
@@ -0,0 +1,138 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+)
+
+type palettedScanner struct {
+	*image.Paletted
+
+	// class maps each palette index to the lowest index of the same color.
+	class [256]uint8
+	// dup reports, for each class, if it is made of more than one index.
+	dup [256]bool
+}
+
+// isUniformIndex indicates if the region r is only made of pixels whose
+// palette index has the same color as idx.
+func (s *palettedScanner) isUniformIndex(r image.Rectangle, idx uint8) bool {
+	w, h := r.Dx(), r.Dy()
+	if w <= 0 || h <= 0 {
+		return true
+	}
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	class := s.class[idx]
+	if !s.dup[class] {
+		return isUniformRegion(s.Pix, i, w, h, s.Stride, idx)
+	}
+	for y := 0; y < h; y++ {
+		for _, v := range s.Pix[i : i+w] {
+			if s.class[v] != class {
+				return false
+			}
+		}
+		i += s.Stride
+	}
+	return true
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// c is converted to the closest color of the palette, and pixels are compared
+// by palette index, palette entries of the same color being considered as
+// equal.
+//
+// The scan stops at the first pixel encountered that is different from c.
+func (s *palettedScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	if len(s.Palette) == 0 {
+		return r.Empty()
+	}
+	return s.isUniformIndex(r, uint8(s.Palette.Index(c)))
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// The scan stops at the first pixel encountered that is different from the
+// previous one.
+func (s *palettedScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	// palette index of the first pixel (top-left)
+	idx := s.ColorIndexAt(r.Min.X, r.Min.Y)
+	if int(idx) >= len(s.Palette) {
+		return false, nil
+	}
+
+	// check if all the pixels of the region are of this color.
+	if s.isUniformIndex(r, idx) {
+		return true, s.Palette[idx]
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// A full scan of the region is performed in order to build the histogram of
+// the palette indices, from which the average color is computed. As it
+// usually isn't a color of the palette, the average of a non-uniform region
+// is returned as a color.RGBA64.
+func (s *palettedScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+
+	var hist [256]uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := s.PixOffset(r.Min.X, y)
+		for _, v := range s.Pix[i : i+r.Dx()] {
+			hist[v]++
+		}
+	}
+
+	// indices out of the palette range count as transparent pixels
+	var sum [4]uint64
+	for i, pc := range s.Palette {
+		if i >= len(hist) {
+			break
+		}
+		if hist[i] == 0 {
+			continue
+		}
+		r, g, b, a := pc.RGBA()
+		sum[0] += uint64(r) * hist[i]
+		sum[1] += uint64(g) * hist[i]
+		sum[2] += uint64(b) * hist[i]
+		sum[3] += uint64(a) * hist[i]
+	}
+	n := uint64(r.Dx() * r.Dy())
+	return false, color.RGBA64{uint16(sum[0] / n), uint16(sum[1] / n), uint16(sum[2] / n), uint16(sum[3] / n)}
+}
+
+// NewPalettedScanner creates a paletted scanner from a paletted image.
+//
+// Duplicate palette entries are found at creation, so the palette of img
+// must not be modified afterwards.
+func NewPalettedScanner(img *image.Paletted) Scanner {
+	s := &palettedScanner{Paletted: img}
+	for i := range s.class {
+		s.class[i] = uint8(i)
+	}
+	type rgba struct{ r, g, b, a uint32 }
+	first := make(map[rgba]uint8)
+	for i, c := range img.Palette {
+		if i >= len(s.class) {
+			break
+		}
+		var k rgba
+		k.r, k.g, k.b, k.a = c.RGBA()
+		if j, ok := first[k]; ok {
+			s.class[i] = j
+			s.dup[j] = true
+			continue
+		}
+		first[k] = uint8(i)
+	}
+	return s
+}
@@ -0,0 +1,92 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"math/rand"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestPalettedScanner(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	// black is duplicated at index 2
+	pal := color.Palette{color.Black, color.White, color.Gray{0}, red}
+	img := image.NewPaletted(image.Rect(0, 0, 3, 2), pal)
+	copy(img.Pix, []byte{
+		0, 2, 1,
+		2, 3, 1,
+	})
+	scanner, err := NewScanner(img)
+	test.Check(t, err)
+
+	checkIsUniformColor(t, scanner, []regionTest{
+		{0, 0, 2, 1, color.Black, true},
+		{0, 0, 1, 2, color.Gray{0}, true},
+		{0, 0, 2, 2, color.Black, false},
+		{2, 0, 3, 2, color.White, true},
+		// converted to the closest palette color
+		{2, 0, 3, 2, color.Gray{250}, true},
+		{1, 1, 2, 2, color.NRGBA{255, 0, 0, 255}, true},
+		{1, 1, 2, 2, color.Black, false},
+	})
+	checkAverageColor(t, scanner, []regionTest{
+		{0, 0, 2, 1, color.Black, true},
+		{0, 1, 1, 2, color.Gray{0}, true},
+		{0, 0, 3, 2, color.RGBA64{0x7fff, 0x5555, 0x5555, 0xffff}, false},
+	})
+}
+
+func TestPalettedScannerAverageColor(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/colorgopher.png")
+	test.Check(t, err)
+	b := src.Bounds()
+	img := image.NewPaletted(b, palette.WebSafe)
+	draw.Draw(img, b, src, b.Min, draw.Src)
+	scanner := NewPalettedScanner(img)
+
+	// compare with the average of the pixel colors
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		x, y := b.Min.X+rnd.Intn(b.Dx()-1), b.Min.Y+rnd.Intn(b.Dy()-1)
+		r := image.Rect(x, y, x+1+rnd.Intn(b.Max.X-x), y+1+rnd.Intn(b.Max.Y-y))
+
+		var sum [4]uint64
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				r, g, b, a := img.At(x, y).RGBA()
+				sum[0], sum[1], sum[2], sum[3] = sum[0]+uint64(r), sum[1]+uint64(g), sum[2]+uint64(b), sum[3]+uint64(a)
+			}
+		}
+		n := uint64(r.Dx() * r.Dy())
+		want := color.RGBA64{uint16(sum[0] / n), uint16(sum[1] / n), uint16(sum[2] / n), uint16(sum[3] / n)}
+
+		uniform, got := scanner.AverageColor(r)
+		if uniform {
+			if got != img.At(r.Min.X, r.Min.Y) {
+				t.Fatalf("AverageColor(%v): want %v, got %v", r, img.At(r.Min.X, r.Min.Y), got)
+			}
+			continue
+		}
+		if got != want {
+			t.Fatalf("AverageColor(%v): want %v, got %v", r, want, got)
+		}
+	}
+}
+
+func benchmarkUniformPaletted(b *testing.B, size int) {
+	img, err := test.LoadPNG("../testdata/big.png")
+	test.CheckB(b, err)
+
+	paletted := image.NewPaletted(img.Bounds(), palette.Plan9)
+	for i := range paletted.Pix {
+		paletted.Pix[i] = 127
+	}
+	benchmarkUniformScannerTiles(b, NewPalettedScanner(paletted), size, palette.Plan9[127])
+}
+
+func BenchmarkUniformPaletted(b *testing.B)        { benchmarkUniformPaletted(b, 0) }
+func BenchmarkUniformPalettedTiles16(b *testing.B) { benchmarkUniformPaletted(b, 16) }
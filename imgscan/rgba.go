@@ -0,0 +1,165 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+)
+
+type rgbaScanner struct {
+	*image.RGBA
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// The scan stops at the first pixel encountered that is different from c.
+func (s *rgbaScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	var (
+		ok   bool       // conversion to color.RGBA ok
+		rgba color.RGBA // c converted to RGBA
+	)
+	// ensure c is a color.RGBA, or convert it
+	if rgba, ok = c.(color.RGBA); !ok {
+		rgba = s.ColorModel().Convert(c).(color.RGBA)
+	}
+
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	px := []byte{rgba.R, rgba.G, rgba.B, rgba.A}
+	return isUniformPixels(s.Pix, i, r.Dx(), r.Dy(), s.Stride, px, nil)
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// The scan stops at the first pixel encountered that is different from the
+// previous one.
+func (s *rgbaScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	// color of the first pixel (top-left)
+	first := s.RGBAAt(r.Min.X, r.Min.Y)
+
+	// check if all the pixels of the region are of this color.
+	if s.IsUniformColor(r, first) {
+		return true, first
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// A full scan of the region is performed in order to determine the average
+// color. As colors are alpha-premultiplied, each channel is averaged
+// independently.
+func (s *rgbaScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+
+	var sum [4]uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := s.PixOffset(r.Min.X, y)
+		row := s.Pix[i : i+4*r.Dx()]
+		for j := 0; j < len(row); j += 4 {
+			sum[0] += uint64(row[j])
+			sum[1] += uint64(row[j+1])
+			sum[2] += uint64(row[j+2])
+			sum[3] += uint64(row[j+3])
+		}
+	}
+	n := uint64(r.Dx() * r.Dy())
+	return false, color.RGBA{uint8(sum[0] / n), uint8(sum[1] / n), uint8(sum[2] / n), uint8(sum[3] / n)}
+}
+
+// NewRGBAScanner creates a RGBA scanner from a RGBA image.
+func NewRGBAScanner(img *image.RGBA) Scanner {
+	return &rgbaScanner{img}
+}
+
+type nrgbaScanner struct {
+	*image.NRGBA
+}
+
+// nrgbaAlphaMask is the mask of the alpha byte of a NRGBA pixel.
+var nrgbaAlphaMask = []byte{0, 0, 0, 0xff}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// Pixels are compared as stored, except that fully transparent pixels are
+// all considered equal, whatever their color channels.
+//
+// The scan stops at the first pixel encountered that is different from c.
+func (s *nrgbaScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	var (
+		ok    bool        // conversion to color.NRGBA ok
+		nrgba color.NRGBA // c converted to NRGBA
+	)
+	// ensure c is a color.NRGBA, or convert it
+	if nrgba, ok = c.(color.NRGBA); !ok {
+		nrgba = s.ColorModel().Convert(c).(color.NRGBA)
+	}
+
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	px := []byte{nrgba.R, nrgba.G, nrgba.B, nrgba.A}
+	var mask []byte
+	if nrgba.A == 0 {
+		mask = nrgbaAlphaMask
+	}
+	return isUniformPixels(s.Pix, i, r.Dx(), r.Dy(), s.Stride, px, mask)
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// The scan stops at the first pixel encountered that is different from the
+// previous one.
+func (s *nrgbaScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	// color of the first pixel (top-left)
+	first := s.NRGBAAt(r.Min.X, r.Min.Y)
+
+	// check if all the pixels of the region are of this color.
+	if s.IsUniformColor(r, first) {
+		return true, first
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// A full scan of the region is performed in order to determine the average
+// color. As colors are not alpha-premultiplied, color channels are averaged
+// weighted by alpha, so that transparent pixels don't contribute to them.
+func (s *nrgbaScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+
+	var sum [4]uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := s.PixOffset(r.Min.X, y)
+		row := s.Pix[i : i+4*r.Dx()]
+		for j := 0; j < len(row); j += 4 {
+			a := uint64(row[j+3])
+			sum[0] += uint64(row[j]) * a
+			sum[1] += uint64(row[j+1]) * a
+			sum[2] += uint64(row[j+2]) * a
+			sum[3] += a
+		}
+	}
+	n := uint64(r.Dx() * r.Dy())
+	if sum[3] == 0 {
+		return false, color.NRGBA{}
+	}
+	return false, color.NRGBA{
+		uint8(sum[0] / sum[3]),
+		uint8(sum[1] / sum[3]),
+		uint8(sum[2] / sum[3]),
+		uint8(sum[3] / n),
+	}
+}
+
+// NewNRGBAScanner creates a NRGBA scanner from a NRGBA image.
+func NewNRGBAScanner(img *image.NRGBA) Scanner {
+	return &nrgbaScanner{img}
+}
@@ -0,0 +1,176 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+)
+
+// pixel64 returns the big-endian encoding of the 16-bit channels r, g, b and
+// a, as stored in the Pix slice of RGBA64 and NRGBA64 images.
+func pixel64(r, g, b, a uint16) []byte {
+	return []byte{
+		uint8(r >> 8), uint8(r),
+		uint8(g >> 8), uint8(g),
+		uint8(b >> 8), uint8(b),
+		uint8(a >> 8), uint8(a),
+	}
+}
+
+type rgba64Scanner struct {
+	*image.RGBA64
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// The scan stops at the first pixel encountered that is different from c.
+func (s *rgba64Scanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	var (
+		ok     bool         // conversion to color.RGBA64 ok
+		rgba64 color.RGBA64 // c converted to RGBA64
+	)
+	// ensure c is a color.RGBA64, or convert it
+	if rgba64, ok = c.(color.RGBA64); !ok {
+		rgba64 = s.ColorModel().Convert(c).(color.RGBA64)
+	}
+
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	px := pixel64(rgba64.R, rgba64.G, rgba64.B, rgba64.A)
+	return isUniformPixels(s.Pix, i, r.Dx(), r.Dy(), s.Stride, px, nil)
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// The scan stops at the first pixel encountered that is different from the
+// previous one.
+func (s *rgba64Scanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	// color of the first pixel (top-left)
+	first := s.RGBA64At(r.Min.X, r.Min.Y)
+
+	// check if all the pixels of the region are of this color.
+	if s.IsUniformColor(r, first) {
+		return true, first
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// A full scan of the region is performed in order to determine the average
+// color. As colors are alpha-premultiplied, each channel is averaged
+// independently.
+func (s *rgba64Scanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+
+	var sum [4]uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := s.PixOffset(r.Min.X, y)
+		row := s.Pix[i : i+8*r.Dx()]
+		for j := 0; j < len(row); j += 8 {
+			sum[0] += uint64(row[j])<<8 | uint64(row[j+1])
+			sum[1] += uint64(row[j+2])<<8 | uint64(row[j+3])
+			sum[2] += uint64(row[j+4])<<8 | uint64(row[j+5])
+			sum[3] += uint64(row[j+6])<<8 | uint64(row[j+7])
+		}
+	}
+	n := uint64(r.Dx() * r.Dy())
+	return false, color.RGBA64{uint16(sum[0] / n), uint16(sum[1] / n), uint16(sum[2] / n), uint16(sum[3] / n)}
+}
+
+// NewRGBA64Scanner creates a RGBA64 scanner from a RGBA64 image.
+func NewRGBA64Scanner(img *image.RGBA64) Scanner {
+	return &rgba64Scanner{img}
+}
+
+type nrgba64Scanner struct {
+	*image.NRGBA64
+}
+
+// nrgba64AlphaMask is the mask of the alpha bytes of a NRGBA64 pixel.
+var nrgba64AlphaMask = []byte{0, 0, 0, 0, 0, 0, 0xff, 0xff}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// Pixels are compared as stored, except that fully transparent pixels are
+// all considered equal, whatever their color channels.
+//
+// The scan stops at the first pixel encountered that is different from c.
+func (s *nrgba64Scanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	var (
+		ok      bool          // conversion to color.NRGBA64 ok
+		nrgba64 color.NRGBA64 // c converted to NRGBA64
+	)
+	// ensure c is a color.NRGBA64, or convert it
+	if nrgba64, ok = c.(color.NRGBA64); !ok {
+		nrgba64 = s.ColorModel().Convert(c).(color.NRGBA64)
+	}
+
+	i := s.PixOffset(r.Min.X, r.Min.Y)
+	px := pixel64(nrgba64.R, nrgba64.G, nrgba64.B, nrgba64.A)
+	var mask []byte
+	if nrgba64.A == 0 {
+		mask = nrgba64AlphaMask
+	}
+	return isUniformPixels(s.Pix, i, r.Dx(), r.Dy(), s.Stride, px, mask)
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// The scan stops at the first pixel encountered that is different from the
+// previous one.
+func (s *nrgba64Scanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	// color of the first pixel (top-left)
+	first := s.NRGBA64At(r.Min.X, r.Min.Y)
+
+	// check if all the pixels of the region are of this color.
+	if s.IsUniformColor(r, first) {
+		return true, first
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// A full scan of the region is performed in order to determine the average
+// color. As colors are not alpha-premultiplied, color channels are averaged
+// weighted by alpha, so that transparent pixels don't contribute to them.
+func (s *nrgba64Scanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+
+	var sum [4]uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := s.PixOffset(r.Min.X, y)
+		row := s.Pix[i : i+8*r.Dx()]
+		for j := 0; j < len(row); j += 8 {
+			a := uint64(row[j+6])<<8 | uint64(row[j+7])
+			sum[0] += (uint64(row[j])<<8 | uint64(row[j+1])) * a
+			sum[1] += (uint64(row[j+2])<<8 | uint64(row[j+3])) * a
+			sum[2] += (uint64(row[j+4])<<8 | uint64(row[j+5])) * a
+			sum[3] += a
+		}
+	}
+	n := uint64(r.Dx() * r.Dy())
+	if sum[3] == 0 {
+		return false, color.NRGBA64{}
+	}
+	return false, color.NRGBA64{
+		uint16(sum[0] / sum[3]),
+		uint16(sum[1] / sum[3]),
+		uint16(sum[2] / sum[3]),
+		uint16(sum[3] / n),
+	}
+}
+
+// NewNRGBA64Scanner creates a NRGBA64 scanner from a NRGBA64 image.
+func NewNRGBA64Scanner(img *image.NRGBA64) Scanner {
+	return &nrgba64Scanner{img}
+}
@@ -0,0 +1,119 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+// checkAverageClose checks that the average colors of 8-bit and 16-bit
+// scanners, for the same region, differ by at most 1 once the latter is
+// reduced to 8 bits.
+func checkAverageClose(t *testing.T, r image.Rectangle, c8 []uint8, c16 []uint16) {
+	t.Helper()
+	for i := range c8 {
+		if d := int(c8[i]) - int(c16[i]/0x101); d < -1 || d > 1 {
+			t.Fatalf("AverageColor(%v): 8-bit channels %v and 16-bit channels %v don't match", r, c8, c16)
+		}
+	}
+}
+
+func TestRGBA64Scanners(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/colorgopher.png")
+	test.Check(t, err)
+	b := src.Bounds()
+
+	// 8-bit channels are expanded to 16 bits without loss, so that
+	// uniformity is the same at both depths.
+	rgba, rgba64 := image.NewRGBA(b), image.NewRGBA64(b)
+	nrgba, nrgba64 := image.NewNRGBA(b), image.NewNRGBA64(b)
+	draw.Draw(rgba, b, src, b.Min, draw.Src)
+	draw.Draw(nrgba, b, src, b.Min, draw.Src)
+	draw.Draw(rgba64, b, rgba, b.Min, draw.Src)
+	draw.Draw(nrgba64, b, nrgba, b.Min, draw.Src)
+
+	pairs := []struct {
+		s8, s16 Scanner
+	}{
+		{NewRGBAScanner(rgba), NewRGBA64Scanner(rgba64)},
+		{NewNRGBAScanner(nrgba), NewNRGBA64Scanner(nrgba64)},
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		x, y := b.Min.X+rnd.Intn(b.Dx()-1), b.Min.Y+rnd.Intn(b.Dy()-1)
+		r := image.Rect(x, y, x+1+rnd.Intn(b.Max.X-x), y+1+rnd.Intn(b.Max.Y-y))
+		if i%2 == 0 {
+			// smaller regions, more likely uniform
+			r = image.Rect(x, y, x+1+rnd.Intn(10), y+1+rnd.Intn(10)).Intersect(b)
+		}
+
+		for _, p := range pairs {
+			u8, c8 := p.s8.IsUniform(r)
+			u16, c16 := p.s16.IsUniform(r)
+			if u8 != u16 {
+				t.Fatalf("%T.IsUniform(%v): want %v, got %v", p.s16, r, u8, u16)
+			}
+			if u8 && !p.s16.IsUniformColor(r, c8) {
+				t.Fatalf("%T.IsUniformColor(%v, %v): want true, got false", p.s16, r, c8)
+			}
+			if u16 && !p.s8.IsUniformColor(r, c16) {
+				t.Fatalf("%T.IsUniformColor(%v, %v): want true, got false", p.s8, r, c16)
+			}
+
+			if u8 {
+				// the color channels of transparent pixels may differ
+				continue
+			}
+			_, c8 = p.s8.AverageColor(r)
+			_, c16 = p.s16.AverageColor(r)
+			switch c8 := c8.(type) {
+			case color.RGBA:
+				c16 := c16.(color.RGBA64)
+				checkAverageClose(t, r, []uint8{c8.R, c8.G, c8.B, c8.A}, []uint16{c16.R, c16.G, c16.B, c16.A})
+			case color.NRGBA:
+				c16 := c16.(color.NRGBA64)
+				checkAverageClose(t, r, []uint8{c8.R, c8.G, c8.B, c8.A}, []uint16{c16.R, c16.G, c16.B, c16.A})
+			}
+		}
+	}
+}
+
+func TestNRGBA64ScannerTransparent(t *testing.T) {
+	img := image.NewNRGBA64(image.Rect(0, 0, 3, 1))
+	img.SetNRGBA64(0, 0, color.NRGBA64{0xffff, 0, 0, 0xffff})
+	img.SetNRGBA64(1, 0, color.NRGBA64{0x1234, 0x5678, 0x9abc, 0})
+	img.SetNRGBA64(2, 0, color.NRGBA64{0, 0, 0, 0})
+	scanner, err := NewScanner(img)
+	test.Check(t, err)
+
+	if !scanner.IsUniformColor(image.Rect(1, 0, 3, 1), color.Transparent) {
+		t.Errorf("want transparent pixels uniform, got not uniform")
+	}
+	if scanner.IsUniformColor(image.Rect(1, 0, 3, 1), color.NRGBA64{0x1234, 0x5678, 0x9abc, 1}) {
+		t.Errorf("want not uniform, got uniform")
+	}
+	_, col := scanner.AverageColor(image.Rect(0, 0, 3, 1))
+	if want := (color.NRGBA64{0xffff, 0, 0, 0x5555}); col != want {
+		t.Errorf("want AverageColor %v, got %v", want, col)
+	}
+}
+
+func benchmarkUniformRGBA64(b *testing.B, size int) {
+	img, err := test.LoadPNG("../testdata/big.png")
+	test.CheckB(b, err)
+
+	rgba64 := image.NewRGBA64(img.Bounds())
+	for i := range rgba64.Pix {
+		rgba64.Pix[i] = 127
+	}
+	scanner := NewRGBA64Scanner(rgba64)
+	c := color.RGBA64{0x7f7f, 0x7f7f, 0x7f7f, 0x7f7f}
+	benchmarkUniformScannerTiles(b, scanner, size, c)
+}
+
+func BenchmarkUniformRGBA64(b *testing.B)        { benchmarkUniformRGBA64(b, 0) }
+func BenchmarkUniformRGBA64Tiles16(b *testing.B) { benchmarkUniformRGBA64(b, 16) }
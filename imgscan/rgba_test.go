@@ -0,0 +1,129 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+func newRGBAFromColors(cols [][]color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, len(cols[0]), len(cols)))
+	for y, row := range cols {
+		for x, c := range row {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func newNRGBAFromColors(cols [][]color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, len(cols[0]), len(cols)))
+	for y, row := range cols {
+		for x, c := range row {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+var (
+	red   = color.RGBA{255, 0, 0, 255}
+	blue  = color.RGBA{0, 0, 255, 255}
+	hred  = color.RGBA{128, 0, 0, 128} // half transparent red
+	nred  = color.NRGBA{255, 0, 0, 255}
+	nblue = color.NRGBA{0, 0, 255, 255}
+	nhred = color.NRGBA{255, 0, 0, 128} // half transparent red
+)
+
+func TestRGBAScanner(t *testing.T) {
+	img := newRGBAFromColors([][]color.RGBA{
+		{red, red, {}},
+		{red, hred, blue},
+	})
+	scanner, err := NewScanner(img)
+	test.Check(t, err)
+
+	checkIsUniformColor(t, scanner, []regionTest{
+		{0, 0, 2, 1, red, true},
+		{0, 0, 2, 1, nred, true},
+		{0, 0, 1, 2, red, true},
+		{0, 0, 2, 2, red, false},
+		{1, 1, 2, 2, nhred, true},
+		{2, 0, 3, 1, color.Transparent, true},
+		{2, 0, 3, 1, color.NRGBA{255, 0, 0, 0}, true},
+		{2, 0, 3, 2, color.Transparent, false},
+	})
+	checkAverageColor(t, scanner, []regionTest{
+		{0, 0, 2, 1, red, true},
+		{2, 1, 3, 2, blue, true},
+		{0, 1, 2, 2, color.RGBA{191, 0, 0, 191}, false},
+		{0, 0, 3, 2, color.RGBA{148, 0, 42, 191}, false},
+	})
+}
+
+func TestNRGBAScanner(t *testing.T) {
+	// the last column is fully transparent, with different color channels
+	img := newNRGBAFromColors([][]color.NRGBA{
+		{nred, nred, {10, 20, 30, 0}},
+		{nhred, nblue, {1, 2, 3, 0}},
+	})
+	scanner, err := NewScanner(img)
+	test.Check(t, err)
+
+	checkIsUniformColor(t, scanner, []regionTest{
+		{0, 0, 2, 1, nred, true},
+		{0, 0, 2, 1, red, true},
+		{0, 0, 2, 2, nred, false},
+		{0, 1, 1, 2, hred, true},
+		{0, 1, 1, 2, nred, false},
+		{2, 0, 3, 2, color.Transparent, true},
+		{1, 0, 3, 2, color.Transparent, false},
+	})
+	checkAverageColor(t, scanner, []regionTest{
+		{0, 0, 2, 1, nred, true},
+		{2, 0, 3, 2, color.NRGBA{10, 20, 30, 0}, true},
+		{0, 0, 2, 2, color.NRGBA{182, 0, 72, 223}, false},
+		// transparent pixels only contribute to alpha
+		{0, 0, 3, 2, color.NRGBA{182, 0, 72, 148}, false},
+	})
+}
+
+func benchmarkUniformRGBA(b *testing.B, size int) {
+	img, err := test.LoadPNG("../testdata/big.png")
+	test.CheckB(b, err)
+
+	rgba := image.NewRGBA(img.Bounds())
+	for i := range rgba.Pix {
+		rgba.Pix[i] = 127
+	}
+	scanner := NewRGBAScanner(rgba)
+	c := color.RGBA{127, 127, 127, 127}
+	benchmarkUniformScannerTiles(b, scanner, size, c)
+}
+
+// benchmarkUniformScannerTiles benchmarks the uniformity check of the tiles
+// of size x size pixels of an image of color c, or of the whole image if
+// size is 0.
+func benchmarkUniformScannerTiles(b *testing.B, s Scanner, size int, c color.Color) {
+	r := s.Bounds()
+	w, h := size, size
+	if size == 0 {
+		w, h = r.Dx(), r.Dy()
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for y := r.Min.Y; y+h <= r.Max.Y; y += h {
+			for x := r.Min.X; x+w <= r.Max.X; x += w {
+				if !s.IsUniformColor(image.Rect(x, y, x+w, y+h), c) {
+					b.Fatal("region should be uniform")
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkUniformRGBA(b *testing.B)        { benchmarkUniformRGBA(b, 0) }
+func BenchmarkUniformRGBATiles16(b *testing.B) { benchmarkUniformRGBA(b, 16) }
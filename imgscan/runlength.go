@@ -0,0 +1,70 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/arl/imgtools/binimg"
+)
+
+type runLengthScanner struct {
+	*binimg.RunLength
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// Only the runs of On pixels intersecting r are scanned.
+func (s *runLengthScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	if r.Empty() {
+		return true
+	}
+	uniform, bit := s.RunLength.IsUniform(r)
+	return uniform && bit == s.Palette.Bit(c)
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// Only the runs of On pixels intersecting r are scanned.
+func (s *runLengthScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	if uniform, bit := s.RunLength.IsUniform(r); uniform {
+		return true, s.Palette.Color(bit)
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// If the region is not uniform, the average color is the color of the
+// majority of its pixels, ties being resolved as binimg.On. Only the pixels of
+// r inside the image bounds are considered.
+func (s *runLengthScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	r = r.Intersect(s.Rect)
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+	if 2*s.Area(r) >= r.Dx()*r.Dy() {
+		return false, s.Palette.Color(binimg.On)
+	}
+	return false, s.Palette.Color(binimg.Off)
+}
+
+// OnRatio returns the proportion of On pixels in the region r, between 0 (no
+// On pixels) and 1 (only On pixels). The ratio of an empty region is 0. Only
+// the pixels of r inside the image bounds are considered.
+func (s *runLengthScanner) OnRatio(r image.Rectangle) float64 {
+	r = r.Intersect(s.Rect)
+	n := r.Dx() * r.Dy()
+	if n <= 0 {
+		return 0
+	}
+	return float64(s.Area(r)) / float64(n)
+}
+
+// NewRunLengthScanner creates a binary scanner from a run-length encoded
+// binary image.
+func NewRunLengthScanner(img *binimg.RunLength) BinaryScanner {
+	return &runLengthScanner{img}
+}
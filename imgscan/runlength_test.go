@@ -0,0 +1,95 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/arl/imgtools/binimg"
+	"github.com/arl/imgtools/internal/test"
+)
+
+func TestRunLengthScanner(t *testing.T) {
+	src, err := test.LoadPNG("../testdata/bwgopher.png")
+	test.Check(t, err)
+	bin := binimg.NewFromImage(src)
+
+	// compare with the byte-per-pixel binary scanner
+	want := NewBinaryScanner(bin)
+	got, err := NewScanner(binimg.RunLengthEncode(bin))
+	test.Check(t, err)
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		x, y := rnd.Intn(470), rnd.Intn(470)
+		r := image.Rect(x, y, x+1+rnd.Intn(480-x), y+1+rnd.Intn(480-y))
+		if i%2 == 0 {
+			// smaller regions, more likely uniform
+			r = image.Rect(x, y, x+1+rnd.Intn(10), y+1+rnd.Intn(10))
+		}
+
+		wu, wc := want.IsUniform(r)
+		gu, gc := got.IsUniform(r)
+		if wu != gu || wc != gc {
+			t.Fatalf("IsUniform(%v): want (%v, %v), got (%v, %v)", r, wu, wc, gu, gc)
+		}
+		for _, c := range []color.Color{binimg.On, color.Black} {
+			if w, g := want.IsUniformColor(r, c), got.IsUniformColor(r, c); w != g {
+				t.Fatalf("IsUniformColor(%v, %v): want %v, got %v", r, c, w, g)
+			}
+		}
+		wu, wc = want.AverageColor(r)
+		gu, gc = got.AverageColor(r)
+		if wu != gu || wc != gc {
+			t.Fatalf("AverageColor(%v): want (%v, %v), got (%v, %v)", r, wu, wc, gu, gc)
+		}
+		if w, g := want.OnRatio(r), got.(BinaryScanner).OnRatio(r); w != g {
+			t.Fatalf("OnRatio(%v): want %v, got %v", r, w, g)
+		}
+	}
+}
+
+func TestRunLengthScannerPalette(t *testing.T) {
+	ss := []string{
+		"000",
+		"100",
+		"011",
+	}
+
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+	img := binimg.RunLengthEncode(newBinaryFromString(ss))
+	img.Palette = &binimg.Palette{OnColor: blue, OffColor: red}
+	scanner := NewRunLengthScanner(img)
+
+	if !scanner.IsUniformColor(image.Rect(1, 0, 3, 2), red) {
+		t.Errorf("want region of Off pixels uniform of the Off color")
+	}
+	if scanner.IsUniformColor(image.Rect(1, 0, 3, 2), blue) {
+		t.Errorf("want region of Off pixels not uniform of the On color")
+	}
+	if uniform, col := scanner.IsUniform(image.Rect(1, 2, 3, 3)); !uniform || col != blue {
+		t.Errorf("want region of On pixels uniform of the On color, got uniform=%v, color=%v", uniform, col)
+	}
+	if uniform, col := scanner.AverageColor(image.Rect(0, 0, 3, 3)); uniform || col != red {
+		t.Errorf("want average color of mostly Off region to be the Off color, got uniform=%v, color=%v", uniform, col)
+	}
+}
+
+func TestRunLengthScannerOutOfBounds(t *testing.T) {
+	ss := []string{
+		"000",
+		"100",
+		"011",
+	}
+	scanner := NewRunLengthScanner(binimg.RunLengthEncode(newBinaryFromString(ss)))
+
+	// only the 6 pixels inside the image bounds are considered
+	r := image.Rect(0, 1, 10, 10)
+	if got := scanner.OnRatio(r); got != 0.5 {
+		t.Errorf("want OnRatio(%v) = 0.5, got %v", r, got)
+	}
+	if uniform, col := scanner.AverageColor(r); uniform || col != binimg.On {
+		t.Errorf("want AverageColor(%v) = (false, On), got (%v, %v)", r, uniform, col)
+	}
+}
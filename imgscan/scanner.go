@@ -37,6 +37,18 @@ type Scanner interface {
 	AverageColor(r image.Rectangle) (bool, color.Color)
 }
 
+// A BinaryScanner is a Scanner of binary images, that can also report the
+// proportion of On pixels of a region.
+type BinaryScanner interface {
+	Scanner
+
+	// OnRatio returns the proportion of On pixels in the region r, between 0
+	// (no On pixels) and 1 (only On pixels). The ratio of an empty region is 0.
+	//
+	// A full scan of the region is performed in order to determine the ratio.
+	OnRatio(r image.Rectangle) float64
+}
+
 // ErrUnsupportedType is returned by NewScanner when an implementation of
 // imgscan.Scanner for the specific image type doesn't exist.
 var ErrUnsupportedType = errors.New("scanner: unsupported image type")
@@ -46,6 +58,8 @@ var ErrUnsupportedType = errors.New("scanner: unsupported image type")
 // The actual scanner implementation depends on the image bit depth and the
 // availability of an implementation. If a specific implementation of Scanner
 // doesn't exist for the type of img, err will be ErrUnsupportedType.
+//
+// See NewScannerWithFallback to scan images of any type.
 func NewScanner(img image.Image) (Scanner, error) {
 	var (
 		s   Scanner
@@ -54,10 +68,45 @@ func NewScanner(img image.Image) (Scanner, error) {
 	switch img.(type) {
 	case *binimg.Image:
 		s = NewBinaryScanner(img.(*binimg.Image))
+	case *binimg.Packed:
+		s = NewPackedScanner(img.(*binimg.Packed))
+	case *binimg.RunLength:
+		s = NewRunLengthScanner(img.(*binimg.RunLength))
 	case *image.Gray:
 		s = NewGrayScanner(img.(*image.Gray))
+	case *image.Gray16:
+		s = NewGray16Scanner(img.(*image.Gray16))
+	case *image.Alpha:
+		s = NewAlphaScanner(img.(*image.Alpha))
+	case *image.Alpha16:
+		s = NewAlpha16Scanner(img.(*image.Alpha16))
+	case *image.CMYK:
+		s = NewCMYKScanner(img.(*image.CMYK))
+	case *image.Paletted:
+		s = NewPalettedScanner(img.(*image.Paletted))
+	case *image.YCbCr:
+		s = NewYCbCrScanner(img.(*image.YCbCr))
+	case *image.RGBA:
+		s = NewRGBAScanner(img.(*image.RGBA))
+	case *image.NRGBA:
+		s = NewNRGBAScanner(img.(*image.NRGBA))
+	case *image.RGBA64:
+		s = NewRGBA64Scanner(img.(*image.RGBA64))
+	case *image.NRGBA64:
+		s = NewNRGBA64Scanner(img.(*image.NRGBA64))
 	default:
 		err = ErrUnsupportedType
 	}
 	return s, err
 }
+
+// NewScannerWithFallback returns a new Scanner of the given image.Image, that
+// is the scanner returned by NewScanner if img type is supported, or a generic
+// scanner, as returned by NewGenericScanner, otherwise.
+func NewScannerWithFallback(img image.Image) Scanner {
+	s, err := NewScanner(img)
+	if err != nil {
+		return NewGenericScanner(img)
+	}
+	return s
+}
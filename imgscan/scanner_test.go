@@ -2,6 +2,8 @@ package imgscan
 
 import (
 	"image"
+	"image/color"
+	"image/color/palette"
 	"testing"
 
 	"github.com/arl/imgtools/binimg"
@@ -14,9 +16,21 @@ func TestScannerSupportedImageTypes(t *testing.T) {
 		want error       // error returned by NewScanner
 	}{
 		{binimg.New(r), nil},
+		{binimg.NewRunLength(r), nil},
+		{binimg.NewPacked(r), nil},
+		{binimg.NewPacked(r).SubImage(image.Rect(3, 5, 13, 11)), nil},
 		{image.NewGray(r), nil},
-		{image.NewRGBA(r), ErrUnsupportedType},
-		{image.NewNRGBA(r), ErrUnsupportedType},
+		{image.NewRGBA(r), nil},
+		{image.NewNRGBA(r), nil},
+		{image.NewRGBA64(r), nil},
+		{image.NewNRGBA64(r), nil},
+		{image.NewGray16(r), nil},
+		{image.NewAlpha(r), nil},
+		{image.NewAlpha16(r), nil},
+		{image.NewCMYK(r), nil},
+		{image.NewPaletted(r, palette.Plan9), nil},
+		{image.NewYCbCr(r, image.YCbCrSubsampleRatio420), nil},
+		{image.NewNYCbCrA(r, image.YCbCrSubsampleRatio420), ErrUnsupportedType},
 	}
 
 	for _, tt := range tests {
@@ -44,3 +58,48 @@ func TestScannerSupportedImageTypes(t *testing.T) {
 		}
 	}
 }
+
+// A regionTest is a test of a scanner method on the region {minx,miny|maxx,maxy}.
+type regionTest struct {
+	minx, miny, maxx, maxy int
+	col                    color.Color
+	uniform                bool
+}
+
+func checkIsUniformColor(t *testing.T, scanner Scanner, tests []regionTest) {
+	t.Helper()
+	for _, tt := range tests {
+		uniform := scanner.IsUniformColor(image.Rect(tt.minx, tt.miny, tt.maxx, tt.maxy), tt.col)
+		if uniform != tt.uniform {
+			t.Errorf("want %v for IsUniformColor(rect{%d,%d|%d,%d}, col:%v), got %v", tt.uniform, tt.minx, tt.miny, tt.maxx, tt.maxy, tt.col, uniform)
+		}
+	}
+}
+
+func checkAverageColor(t *testing.T, scanner Scanner, tests []regionTest) {
+	t.Helper()
+	for _, tt := range tests {
+		uniform, col := scanner.AverageColor(image.Rect(tt.minx, tt.miny, tt.maxx, tt.maxy))
+		if uniform != tt.uniform {
+			t.Errorf("want uniform=%v for AverageColor(rect{%d,%d|%d,%d}), got %v", tt.uniform, tt.minx, tt.miny, tt.maxx, tt.maxy, uniform)
+		}
+		if col != tt.col {
+			t.Errorf("want color=%v for AverageColor(rect{%d,%d|%d,%d}), got %v", tt.col, tt.minx, tt.miny, tt.maxx, tt.maxy, col)
+		}
+	}
+}
+
+func TestNewScannerWithFallback(t *testing.T) {
+	r := image.Rect(0, 0, 16, 16)
+	if s := NewScannerWithFallback(image.NewGray(r)); s == nil {
+		t.Errorf("want a scanner, got nil")
+	} else if _, ok := s.(*grayScanner); !ok {
+		t.Errorf("want a gray scanner for a gray image, got %T", s)
+	}
+	img := image.NewNYCbCrA(r, image.YCbCrSubsampleRatio420)
+	if s := NewScannerWithFallback(img); s == nil {
+		t.Errorf("want a scanner, got nil")
+	} else if _, ok := s.(*genericScanner); !ok {
+		t.Errorf("want a generic scanner for an unsupported image type, got %T", s)
+	}
+}
@@ -0,0 +1,173 @@
+package imgscan
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/bits"
+)
+
+// spread returns a word holding 8 copies of v.
+func spread(v byte) uint64 {
+	return uint64(v) * 0x0101010101010101
+}
+
+// isUniformBytes reports whether all the bytes of b are equal to v.
+//
+// The first and last 8 bytes of b are compared to v by loading uint64 words.
+// The bytes in between are then all equal to v if b is equal to itself
+// shifted by 8 bytes, which bytes.Equal checks with vectorized instructions.
+func isUniformBytes(b []byte, v byte) bool {
+	n := len(b)
+	if n < 8 {
+		for i := 0; i < n; i++ {
+			if b[i] != v {
+				return false
+			}
+		}
+		return true
+	}
+
+	w := spread(v)
+	if (binary.LittleEndian.Uint64(b)^w)|(binary.LittleEndian.Uint64(b[n-8:])^w) != 0 {
+		return false
+	}
+	return n <= 16 || bytes.Equal(b[8:], b[:n-8])
+}
+
+// isUniformRegion reports whether all the bytes of a rectangular region of pix
+// are equal to v. The region starts at index i of pix and is made of h lines
+// of w bytes, vertically adjacent lines being stride bytes apart.
+//
+// When the lines are contiguous (i.e stride equals w), the region is checked
+// as a single range of bytes.
+func isUniformRegion(pix []byte, i, w, h, stride int, v byte) bool {
+	if w <= 0 || h <= 0 {
+		return true
+	}
+	if stride == w {
+		return isUniformBytes(pix[i:i+w*h], v)
+	}
+	if w < 8 {
+		for y := 0; y < h; y++ {
+			if !isUniformBytes(pix[i:i+w], v) {
+				return false
+			}
+			i += stride
+		}
+		return true
+	}
+	if w > 32 {
+		// once the first line is known to be uniform, the other lines are
+		// compared to it with bytes.Equal, that uses vectorized instructions
+		// and makes a single call per line.
+		first := pix[i : i+w]
+		if !isUniformBytes(first, v) {
+			return false
+		}
+		for y := 1; y < h; y++ {
+			i += stride
+			if !bytes.Equal(pix[i:i+w], first) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// lines from 8 to 32 bytes are entirely covered by at most 4 overlapping
+	// word loads, checking them inline saves a function call per line.
+	x := spread(v)
+	for y := 0; y < h; y++ {
+		b := pix[i : i+w]
+		acc := (binary.LittleEndian.Uint64(b) ^ x) | (binary.LittleEndian.Uint64(b[w-8:]) ^ x)
+		if w > 16 {
+			acc |= (binary.LittleEndian.Uint64(b[8:]) ^ x) | (binary.LittleEndian.Uint64(b[w-16:]) ^ x)
+		}
+		if acc != 0 {
+			return false
+		}
+		i += stride
+	}
+	return true
+}
+
+// isUniformPixels reports whether all the pixels of a rectangular region of
+// pix are equal to px, a pixel of 1, 2, 4 or 8 bytes. The region starts at
+// index i of pix and is made of h lines of w pixels, vertically adjacent lines
+// being stride bytes apart.
+//
+// If mask is not nil, it has the length of px and only the bits set in mask
+// are compared.
+//
+// As the pixel size divides 8, words loaded at multiples of 8 bytes from the
+// start of a line always hold whole pixels, in the same order. The tail of a
+// line is checked with a single word load, overlapping the previous words.
+func isUniformPixels(pix []byte, i, w, h, stride int, px, mask []byte) bool {
+	if w <= 0 || h <= 0 {
+		return true
+	}
+	size := len(px)
+	if mask == nil {
+		mask = []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}[:size]
+	}
+	n := w * size
+	if stride == n {
+		// contiguous lines, check the region as a single line
+		n *= h
+		h = 1
+	}
+	if n < 8 {
+		for y := 0; y < h; y++ {
+			for k, v := range pix[i : i+n] {
+				if (v^px[k%size])&mask[k%size] != 0 {
+					return false
+				}
+			}
+			i += stride
+		}
+		return true
+	}
+
+	var wpx, wmask [8]byte
+	for k := range wpx {
+		wpx[k], wmask[k] = px[k%size], mask[k%size]
+	}
+	x := binary.LittleEndian.Uint64(wpx[:])
+	m := binary.LittleEndian.Uint64(wmask[:])
+	for y := 0; y < h; y++ {
+		b := pix[i : i+n]
+		k := 0
+		for ; k+32 <= n; k += 32 {
+			acc := (binary.LittleEndian.Uint64(b[k:]) ^ x) | (binary.LittleEndian.Uint64(b[k+8:]) ^ x) |
+				(binary.LittleEndian.Uint64(b[k+16:]) ^ x) | (binary.LittleEndian.Uint64(b[k+24:]) ^ x)
+			if acc&m != 0 {
+				return false
+			}
+		}
+		for ; k+8 <= n; k += 8 {
+			if (binary.LittleEndian.Uint64(b[k:])^x)&m != 0 {
+				return false
+			}
+		}
+		if k < n && (binary.LittleEndian.Uint64(b[n-8:])^x)&m != 0 {
+			return false
+		}
+		i += stride
+	}
+	return true
+}
+
+// popCount returns the number of bits set in b.
+//
+// Bits are counted 8 bytes at a time, by loading uint64 words, the remaining
+// tail bytes being counted one at a time.
+func popCount(b []byte) int {
+	n := 0
+	for len(b) >= 8 {
+		n += bits.OnesCount64(binary.LittleEndian.Uint64(b))
+		b = b[8:]
+	}
+	for _, v := range b {
+		n += bits.OnesCount8(v)
+	}
+	return n
+}
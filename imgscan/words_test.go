@@ -0,0 +1,158 @@
+package imgscan
+
+import (
+	"testing"
+)
+
+func TestIsUniformBytes(t *testing.T) {
+	buf := make([]byte, 64)
+	for i := range buf {
+		buf[i] = 0xaa
+	}
+
+	// check all the combinations of unaligned heads and tails
+	for start := 0; start < 16; start++ {
+		for end := start; end <= len(buf); end++ {
+			b := buf[start:end]
+			if !isUniformBytes(b, 0xaa) {
+				t.Fatalf("want buf[%d:%d] uniform, got not uniform", start, end)
+			}
+			if len(b) != 0 && isUniformBytes(b, 0xab) {
+				t.Fatalf("want buf[%d:%d] not uniform of another value, got uniform", start, end)
+			}
+			// modify each byte in turn
+			for i := range b {
+				b[i] = 0
+				if isUniformBytes(b, 0xaa) {
+					t.Fatalf("want buf[%d:%d] not uniform with byte %d modified, got uniform", start, end, i)
+				}
+				b[i] = 0xaa
+			}
+		}
+	}
+}
+
+func TestIsUniformRegion(t *testing.T) {
+	// 4x3 region of 1s at index 1, in a 6 bytes stride buffer
+	pix := []byte{
+		0, 0, 0, 0, 0, 0,
+		0, 1, 1, 1, 1, 0,
+		0, 1, 1, 1, 1, 0,
+		0, 1, 1, 1, 1, 0,
+	}
+
+	var tests = []struct {
+		i, w, h, stride int
+		want            bool
+	}{
+		{7, 4, 3, 6, true},
+		{6, 4, 3, 6, false},
+		{7, 5, 3, 6, false},
+		{1, 4, 3, 6, false},
+		{7, 4, 0, 6, true},
+		{7, 0, 3, 6, true},
+		// contiguous lines
+		{7, 4, 1, 4, true},
+		{7, 2, 2, 2, true},
+		{7, 4, 2, 4, false},
+	}
+
+	for _, tt := range tests {
+		got := isUniformRegion(pix, tt.i, tt.w, tt.h, tt.stride, 1)
+		if got != tt.want {
+			t.Errorf("isUniformRegion(i:%d, w:%d, h:%d, stride:%d), want %v, got %v", tt.i, tt.w, tt.h, tt.stride, tt.want, got)
+		}
+	}
+}
+
+func TestIsUniformRegionLineLengths(t *testing.T) {
+	// lines shorter than 8 bytes, from 8 to 32 bytes and longer than 32
+	// bytes take different paths, check all line lengths with every byte of
+	// the region modified in turn
+	const stride = 80
+	pix := make([]byte, 4*stride)
+	for w := 1; w <= stride-2; w++ {
+		if !isUniformRegion(pix, 1, w, 3, stride, 0) {
+			t.Fatalf("want region of width %d uniform, got not uniform", w)
+		}
+		if isUniformRegion(pix, 1, w, 3, stride, 1) {
+			t.Fatalf("want region of width %d not uniform of another value, got uniform", w)
+		}
+		for y := 0; y < 3; y++ {
+			for x := 0; x < w; x++ {
+				i := 1 + y*stride + x
+				pix[i] = 1
+				if isUniformRegion(pix, 1, w, 3, stride, 0) {
+					t.Fatalf("want region of width %d not uniform with pixel (%d,%d) modified, got uniform", w, x, y)
+				}
+				pix[i] = 0
+			}
+		}
+	}
+}
+
+func TestIsUniformPixels(t *testing.T) {
+	for _, size := range []int{1, 2, 4, 8} {
+		px := []byte{1, 2, 3, 4, 5, 6, 7, 8}[:size]
+		for w := 0; w <= 9; w++ {
+			// w pixels by 3 lines, with a 1 pixel margin on both sides
+			stride := (w + 2) * size
+			pix := make([]byte, 3*stride)
+			for y := 0; y < 3; y++ {
+				for x := 1; x <= w; x++ {
+					copy(pix[y*stride+x*size:], px)
+				}
+			}
+			if !isUniformPixels(pix, size, w, 3, stride, px, nil) {
+				t.Fatalf("size %d, width %d: want uniform, got not uniform", size, w)
+			}
+			// modify each byte in turn
+			for i := range pix {
+				x := i % stride / size
+				if x == 0 || x > w {
+					continue
+				}
+				pix[i] ^= 0x10
+				if isUniformPixels(pix, size, w, 3, stride, px, nil) {
+					t.Fatalf("size %d, width %d: want not uniform with byte %d modified, got uniform", size, w, i)
+				}
+				// modifications of bits out of the mask are ignored
+				mask := make([]byte, size)
+				for k := range mask {
+					mask[k] = 0xff
+				}
+				mask[i%size] = 0xef
+				if !isUniformPixels(pix, size, w, 3, stride, px, mask) {
+					t.Fatalf("size %d, width %d: want masked uniform with byte %d modified, got not uniform", size, w, i)
+				}
+				pix[i] ^= 0x10
+			}
+		}
+	}
+
+	// contiguous lines
+	pix := []byte{0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1}
+	if !isUniformPixels(pix, 2, 3, 2, 6, []byte{0, 1}, nil) {
+		t.Errorf("contiguous lines: want uniform, got not uniform")
+	}
+	if isUniformPixels(pix, 1, 3, 2, 6, []byte{0, 1}, nil) {
+		t.Errorf("contiguous lines: want not uniform, got uniform")
+	}
+}
+
+func TestPopCount(t *testing.T) {
+	buf := make([]byte, 40)
+	want := 0
+	for i := range buf {
+		buf[i] = byte(i * 37)
+		for v := buf[i]; v != 0; v >>= 1 {
+			want += int(v & 1)
+		}
+	}
+	if got := popCount(buf); got != want {
+		t.Errorf("want %d bits set, got %d", want, got)
+	}
+	if got := popCount(buf[:0]); got != 0 {
+		t.Errorf("want no bits set in an empty slice, got %d", got)
+	}
+}
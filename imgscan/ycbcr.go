@@ -0,0 +1,98 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+)
+
+type ycbcrScanner struct {
+	*image.YCbCr
+}
+
+// chromaRegion returns the index of the chroma sample of the top-left pixel
+// of the region r, and the size of the region of the chroma planes, in
+// samples, covering r.
+func (s *ycbcrScanner) chromaRegion(r image.Rectangle) (i, w, h int) {
+	i = s.COffset(r.Min.X, r.Min.Y)
+	w = s.COffset(r.Max.X-1, r.Min.Y) - i + 1
+	h = (s.COffset(r.Min.X, r.Max.Y-1)-i)/s.CStride + 1
+	return i, w, h
+}
+
+// IsUniformColor indicates if the region r is only made of pixels of color c.
+//
+// The luma plane is checked first, then the regions of the chroma planes
+// covering r, that are smaller when chroma is subsampled.
+//
+// The scan stops at the first pixel encountered that is different from c.
+func (s *ycbcrScanner) IsUniformColor(r image.Rectangle, c color.Color) bool {
+	var (
+		ok    bool        // conversion to color.YCbCr ok
+		ycbcr color.YCbCr // c converted to YCbCr
+	)
+	// ensure c is a color.YCbCr, or convert it
+	if ycbcr, ok = c.(color.YCbCr); !ok {
+		ycbcr = s.ColorModel().Convert(c).(color.YCbCr)
+	}
+
+	if r.Empty() {
+		return true
+	}
+	yi := s.YOffset(r.Min.X, r.Min.Y)
+	if !isUniformRegion(s.Y, yi, r.Dx(), r.Dy(), s.YStride, ycbcr.Y) {
+		return false
+	}
+	ci, cw, ch := s.chromaRegion(r)
+	return isUniformRegion(s.Cb, ci, cw, ch, s.CStride, ycbcr.Cb) &&
+		isUniformRegion(s.Cr, ci, cw, ch, s.CStride, ycbcr.Cr)
+}
+
+// IsUniform indicates if the region r is uniform. If that is the case, the
+// uniform color is returned, otherwise the returned color is nil.
+//
+// The scan stops at the first pixel encountered that is different from the
+// previous one.
+func (s *ycbcrScanner) IsUniform(r image.Rectangle) (bool, color.Color) {
+	// color of the first pixel (top-left)
+	first := s.YCbCrAt(r.Min.X, r.Min.Y)
+
+	// check if all the pixels of the region are of this color.
+	if s.IsUniformColor(r, first) {
+		return true, first
+	}
+	return false, nil
+}
+
+// AverageColor indicates wether the region is uniform and the average color
+// of the region r. If all the pixels have the same color (i.e the region is
+// uniform) then the average color is that color.
+//
+// A full scan of the region is performed in order to determine the average
+// color. The average is computed in the YCbCr space, each chroma sample being
+// weighted by the number of pixels of r it is shared by.
+func (s *ycbcrScanner) AverageColor(r image.Rectangle) (bool, color.Color) {
+	if uniform, col := s.IsUniform(r); uniform {
+		return true, col
+	}
+
+	var sumY, sumCb, sumCr uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		yi := s.YOffset(r.Min.X, y)
+		for _, v := range s.Y[yi : yi+r.Dx()] {
+			sumY += uint64(v)
+		}
+		for x := r.Min.X; x < r.Max.X; x++ {
+			ci := s.COffset(x, y)
+			sumCb += uint64(s.Cb[ci])
+			sumCr += uint64(s.Cr[ci])
+		}
+	}
+	n := uint64(r.Dx() * r.Dy())
+	return false, color.YCbCr{uint8(sumY / n), uint8(sumCb / n), uint8(sumCr / n)}
+}
+
+// NewYCbCrScanner creates a YCbCr scanner from a YCbCr image, of any chroma
+// subsample ratio.
+func NewYCbCrScanner(img *image.YCbCr) Scanner {
+	return &ycbcrScanner{img}
+}
@@ -0,0 +1,95 @@
+package imgscan
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/arl/imgtools/internal/test"
+)
+
+var subsampleRatios = []image.YCbCrSubsampleRatio{
+	image.YCbCrSubsampleRatio444,
+	image.YCbCrSubsampleRatio422,
+	image.YCbCrSubsampleRatio420,
+	image.YCbCrSubsampleRatio440,
+	image.YCbCrSubsampleRatio411,
+	image.YCbCrSubsampleRatio410,
+}
+
+// newBlockYCbCr returns a YCbCr image whose planes are made of blocks of
+// uniform samples, chroma blocks being shifted relatively to luma blocks.
+func newBlockYCbCr(r image.Rectangle, ratio image.YCbCrSubsampleRatio) *image.YCbCr {
+	img := image.NewYCbCr(r, ratio)
+	for i := range img.Y {
+		x, y := i%img.YStride, i/img.YStride
+		img.Y[i] = uint8((x/8 + y/8) % 2 * 100)
+	}
+	for i := range img.Cb {
+		x, y := i%img.CStride, i/img.CStride
+		img.Cb[i] = uint8(((x+1)/4 + y/4) % 2 * 50)
+		img.Cr[i] = uint8(((x+1)/4 + (y+1)/4) % 2 * 30)
+	}
+	return img
+}
+
+func TestYCbCrScanner(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, ratio := range subsampleRatios {
+		// odd bounds, so that chroma samples are shared with pixels out
+		// of the image
+		img := newBlockYCbCr(image.Rect(-3, -5, 61, 45), ratio)
+		scanner, err := NewScanner(img)
+		test.Check(t, err)
+
+		for i := 0; i < 1000; i++ {
+			x, y := -3+rnd.Intn(63), -5+rnd.Intn(49)
+			r := image.Rect(x, y, x+1+rnd.Intn(10), y+1+rnd.Intn(10)).Intersect(img.Rect)
+			if i%10 == 0 {
+				r = image.Rect(x, y, x+1+rnd.Intn(61-x), y+1+rnd.Intn(45-y))
+			}
+
+			// compare with the pixel colors
+			first := img.YCbCrAt(r.Min.X, r.Min.Y)
+			uniform := true
+			var sum [3]uint64
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				for x := r.Min.X; x < r.Max.X; x++ {
+					c := img.YCbCrAt(x, y)
+					uniform = uniform && c == first
+					sum[0], sum[1], sum[2] = sum[0]+uint64(c.Y), sum[1]+uint64(c.Cb), sum[2]+uint64(c.Cr)
+				}
+			}
+			n := uint64(r.Dx() * r.Dy())
+			avg := color.YCbCr{uint8(sum[0] / n), uint8(sum[1] / n), uint8(sum[2] / n)}
+
+			if got := scanner.IsUniformColor(r, first); got != uniform {
+				t.Fatalf("ratio %v, IsUniformColor(%v, %v): want %v, got %v", ratio, r, first, uniform, got)
+			}
+			gu, gc := scanner.AverageColor(r)
+			if gu != uniform || gc != avg {
+				t.Fatalf("ratio %v, AverageColor(%v): want (%v, %v), got (%v, %v)", ratio, r, uniform, avg, gu, gc)
+			}
+		}
+	}
+}
+
+func TestYCbCrScannerSubsampledChroma(t *testing.T) {
+	// uniform luma, the chroma sample of the last 2 columns differs
+	img := image.NewYCbCr(image.Rect(0, 0, 4, 2), image.YCbCrSubsampleRatio420)
+	img.Cb[1] = 10
+	scanner, err := NewScanner(img)
+	test.Check(t, err)
+
+	checkIsUniformColor(t, scanner, []regionTest{
+		{0, 0, 2, 2, color.YCbCr{0, 0, 0}, true},
+		{0, 0, 3, 1, color.YCbCr{0, 0, 0}, false},
+		{2, 1, 4, 2, color.YCbCr{0, 10, 0}, true},
+		{1, 0, 3, 2, color.YCbCr{0, 0, 0}, false},
+	})
+	checkAverageColor(t, scanner, []regionTest{
+		{0, 0, 4, 2, color.YCbCr{0, 5, 0}, false},
+		{1, 0, 4, 2, color.YCbCr{0, 6, 0}, false},
+	})
+}
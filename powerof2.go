@@ -25,9 +25,9 @@ func Pow2Roundup(x int) int {
 }
 
 // newImage creates a new image having the same type as img, with r as
-// bounds.
+// bounds. Binary images share the palette of img.
 func newImage(img image.Image, r image.Rectangle) (draw.Image, error) {
-	switch img.(type) {
+	switch img := img.(type) {
 	case *image.Alpha:
 		return image.NewAlpha(r), nil
 	case *image.Alpha16:
@@ -47,7 +47,15 @@ func newImage(img image.Image, r image.Rectangle) (draw.Image, error) {
 	case *image.RGBA64:
 		return image.NewRGBA64(r), nil
 	case *binimg.Image:
-		return binimg.New(r), nil
+		return binimg.NewWithPalette(r, img.Palette), nil
+	case *binimg.Packed:
+		p := binimg.NewPacked(r)
+		p.Palette = img.Palette
+		return p, nil
+	case *binimg.RunLength:
+		rl := binimg.NewRunLength(r)
+		rl.Palette = img.Palette
+		return rl, nil
 	default:
 		return nil, errors.New("unsupported image type")
 	}
@@ -59,7 +67,7 @@ func newImage(img image.Image, r image.Rectangle) (draw.Image, error) {
 //
 // Note: if src dimensions is already a power-of-2 square image, it is returned
 // as-is.This is an helper function supports the standard Go image and
-// binimg.Image types.
+// binimg.Image, binimg.Packed and binimg.RunLength types.
 func PowerOf2Image(src image.Image, pad color.Color) (image.Image, error) {
 	if IsPowerOf2Image(src) {
 		return src, nil
@@ -87,6 +87,24 @@ func TestPowerOf2Image(t *testing.T) {
 			t.Errorf("want same images bytes, got different: %v", err)
 		}
 	})
+	t.Run("binary images keep their palette", func(t *testing.T) {
+		p := &binimg.Palette{OnColor: blue, OffColor: red}
+		m := binimg.NewWithPalette(image.Rect(0, 0, 3, 5), p)
+		m.SetRect(m.Bounds(), binimg.On)
+		dst, err := PowerOf2Image(m, red)
+		test.Check(t, err)
+
+		bin := dst.(*binimg.Image)
+		if bin.Palette != p {
+			t.Errorf("want padded image to share the palette of the original image")
+		}
+		if topLeft := dst.At(0, 0); topLeft != blue {
+			t.Errorf("want top-left pixel color unchanged (blue), got %v", topLeft)
+		}
+		if bottomRight := dst.At(7, 7); bottomRight != red {
+			t.Errorf("want bottom-right pixel red (padding), got %v", bottomRight)
+		}
+	})
 	t.Run("return a square image of the same type", func(t *testing.T) {
 		var tests = []struct {
 			org draw.Image
@@ -94,6 +112,8 @@ func TestPowerOf2Image(t *testing.T) {
 			{image.NewGray(image.Rect(2, 3, 4, 5))},
 			{image.NewGray16(image.Rect(2, 3, 4, 5))},
 			{binimg.New(image.Rect(0, -1, 12, 14))},
+			{binimg.NewPacked(image.Rect(0, -1, 12, 14))},
+			{binimg.NewRunLength(image.Rect(0, -1, 12, 14))},
 			{image.NewAlpha(image.Rect(2, 3, 4, 5))},
 		}
 